@@ -0,0 +1,115 @@
+// Copyright (c) 2021 Red Hat, Inc.
+// Copyright Contributors to the Open Cluster Management project
+
+// compare-results compares the junit reports or the JSON summaries of several runs of the suite, and
+// reports the flaky specs, the duration regressions and the new failures against the baseline run, e.g.
+//
+//	go run ./cmd/compare-results -baseline=nightly-1/results.xml nightly-2/results.xml nightly-3/results.json
+//
+// With -history the runs are added to a JSON store and compared with the last -last runs in the store, a run
+// is identified by -run-id, or by the hash of its results if not set, so adding the same results again
+// replaces the run instead of adding a duplicate.
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/stolostron/observability-e2e-test/pkg/reporters"
+)
+
+func main() {
+	var (
+		baselineFile string
+		historyFile  string
+		runID        string
+		last         int
+		ratio        float64
+		minDelta     float64
+		output       string
+	)
+	flag.StringVar(&baselineFile, "baseline", "", "The results of the baseline run, defaults to the first run.")
+	flag.StringVar(&historyFile, "history", "", "The JSON file to keep the results of the runs, the runs are added to it.")
+	flag.StringVar(&runID, "run-id", "", "The ID of the run added to the history, e.g. the CI build ID, defaults to the hash of the results.")
+	flag.IntVar(&last, "last", 0, "Compare the last N runs in the history, all runs if not set.")
+	flag.Float64Var(&ratio, "ratio", 1.5, "A spec regressed if it took more than ratio times as long as in the baseline run.")
+	flag.Float64Var(&minDelta, "min-delta", 60, "A spec regressed if it took more than min-delta seconds longer than in the baseline run.")
+	flag.StringVar(&output, "output", "text", "The output format, text or json.")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "Usage: %s [flags] results.xml|results.json...\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	if err := run(flag.Args(), baselineFile, historyFile, runID, last, ratio, minDelta, output); err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		os.Exit(1)
+	}
+}
+
+func run(files []string, baselineFile, historyFile, runID string, last int, ratio, minDelta float64, output string) error {
+	runs := []*reporters.Summary{}
+	if historyFile != "" {
+		if runID != "" && len(files) > 1 {
+			return fmt.Errorf("-run-id can only be set with the results of a single run")
+		}
+		history, err := reporters.LoadHistory(historyFile)
+		if err != nil {
+			return err
+		}
+		for _, file := range files {
+			s, err := reporters.LoadSummary(file)
+			if err != nil {
+				return err
+			}
+			id := runID
+			if id == "" {
+				if id, err = reporters.RunID(s); err != nil {
+					return err
+				}
+			}
+			history.Add(id, s)
+		}
+		if err := history.Save(historyFile); err != nil {
+			return err
+		}
+		runs = history.Summaries(last)
+	} else {
+		for _, file := range files {
+			s, err := reporters.LoadSummary(file)
+			if err != nil {
+				return err
+			}
+			runs = append(runs, s)
+		}
+	}
+
+	var baseline *reporters.Summary
+	if baselineFile != "" {
+		var err error
+		if baseline, err = reporters.LoadSummary(baselineFile); err != nil {
+			return err
+		}
+	} else if len(runs) > 0 {
+		baseline, runs = runs[0], runs[1:]
+	} else {
+		return fmt.Errorf("no results to compare")
+	}
+
+	c := reporters.Compare(baseline, runs, reporters.CompareOptions{Ratio: ratio, MinDelta: minDelta})
+	switch output {
+	case "json":
+		data, err := json.MarshalIndent(c, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(data))
+	case "text":
+		c.Print(os.Stdout)
+	default:
+		return fmt.Errorf("unknown output format %s", output)
+	}
+	return nil
+}
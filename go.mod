@@ -5,7 +5,9 @@ go 1.14
 require (
 	github.com/fsnotify/fsnotify v1.5.1 // indirect
 	github.com/ghodss/yaml v1.0.0
+	github.com/golang/snappy v0.0.4
 	github.com/hashicorp/go-version v1.3.0
+	github.com/minio/minio-go/v7 v7.0.7
 	github.com/onsi/ginkgo v1.16.4
 	github.com/onsi/gomega v1.10.1
 	github.com/prometheus/alertmanager v0.23.0
@@ -13,6 +15,7 @@ require (
 	github.com/stretchr/testify v1.7.0
 	golang.org/x/net v0.0.0-20210805182204-aaa1db679c0d // indirect
 	golang.org/x/sys v0.0.0-20210915083310-ed5796bab164 // indirect
+	google.golang.org/protobuf v1.26.0-rc.1
 	gopkg.in/yaml.v2 v2.4.0
 	k8s.io/api v0.17.2
 	k8s.io/apiextensions-apiserver v0.17.2
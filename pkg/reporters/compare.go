@@ -0,0 +1,212 @@
+// Copyright (c) 2021 Red Hat, Inc.
+// Copyright Contributors to the Open Cluster Management project
+
+package reporters
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// LoadSummary loads the results of a run from the JSON summary or the junit report
+func LoadSummary(file string) (*Summary, error) {
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	if strings.EqualFold(filepath.Ext(file), ".json") {
+		s := NewSummary("")
+		if err := json.Unmarshal(data, s); err != nil {
+			return nil, fmt.Errorf("failed to parse the JSON summary %s: %v", file, err)
+		}
+		return s, nil
+	}
+
+	suite := JUnitTestSuite{}
+	if err := xml.Unmarshal(data, &suite); err != nil {
+		return nil, fmt.Errorf("failed to parse the junit report %s: %v", file, err)
+	}
+	s := NewSummary(suite.Name)
+	s.Time = suite.Time
+	for _, testCase := range suite.TestCases {
+		s.Add(specResult(testCase))
+	}
+	return s, nil
+}
+
+// Run is a run of the suite in the results history
+type Run struct {
+	ID      string   `json:"id"`
+	Summary *Summary `json:"summary"`
+}
+
+// History is the results of the runs kept in a JSON file, so the trends can be computed offline
+type History struct {
+	Runs []Run `json:"runs"`
+}
+
+// LoadHistory loads the history from the file, a missing file is an empty history
+func LoadHistory(file string) (*History, error) {
+	h := &History{Runs: []Run{}}
+	data, err := ioutil.ReadFile(file)
+	if os.IsNotExist(err) {
+		return h, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, h); err != nil {
+		return nil, fmt.Errorf("failed to parse the history %s: %v", file, err)
+	}
+	return h, nil
+}
+
+// RunID returns the ID of the run derived from the content of its summary, so adding the same results
+// twice is detected while the runs reported to the same file are kept apart
+func RunID(s *Summary) (string, error) {
+	data, err := json.Marshal(s)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("sha256:%x", sha256.Sum256(data))[:19], nil
+}
+
+// Add adds the run to the history, the run with the same ID is replaced
+func (h *History) Add(id string, s *Summary) {
+	for i, run := range h.Runs {
+		if run.ID == id {
+			h.Runs[i].Summary = s
+			return
+		}
+	}
+	h.Runs = append(h.Runs, Run{ID: id, Summary: s})
+}
+
+// Summaries returns the summaries of the last n runs, all runs if n is not positive
+func (h *History) Summaries(n int) []*Summary {
+	runs := h.Runs
+	if n > 0 && len(runs) > n {
+		runs = runs[len(runs)-n:]
+	}
+	summaries := []*Summary{}
+	for _, run := range runs {
+		summaries = append(summaries, run.Summary)
+	}
+	return summaries
+}
+
+// Save writes the history to the file as JSON
+func (h *History) Save(file string) error {
+	data, err := json.MarshalIndent(h, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(file), os.ModePerm); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(file, data, 0644)
+}
+
+// FlakySpec is a spec that both passed and failed in the compared runs
+type FlakySpec struct {
+	Name   string `json:"name"`
+	Passed int    `json:"passed"`
+	Failed int    `json:"failed"`
+}
+
+// DurationRegression is a spec that took longer in the current run than in the baseline run
+type DurationRegression struct {
+	Name     string  `json:"name"`
+	Baseline float64 `json:"baseline"`
+	Current  float64 `json:"current"`
+}
+
+// Comparison is the result of comparing the runs with the baseline run
+type Comparison struct {
+	Flaky       []FlakySpec          `json:"flaky"`
+	Regressions []DurationRegression `json:"regressions"`
+	NewFailures []SpecResult         `json:"newFailures"`
+}
+
+// CompareOptions are the thresholds of the duration regressions, a spec regressed if it took more than
+// Ratio times and MinDelta seconds longer than in the baseline run
+type CompareOptions struct {
+	Ratio    float64
+	MinDelta float64
+}
+
+// Compare compares the runs in order with the baseline run, the flaky specs are found in all runs, the
+// duration regressions and the new failures are found in the last run
+func Compare(baseline *Summary, runs []*Summary, opts CompareOptions) Comparison {
+	c := Comparison{
+		Flaky:       []FlakySpec{},
+		Regressions: []DurationRegression{},
+		NewFailures: []SpecResult{},
+	}
+
+	flips := map[string]*FlakySpec{}
+	for _, s := range append([]*Summary{baseline}, runs...) {
+		for _, r := range s.Specs {
+			if flips[r.Name] == nil {
+				flips[r.Name] = &FlakySpec{Name: r.Name}
+			}
+			switch r.State {
+			case SpecPassed:
+				flips[r.Name].Passed++
+			case SpecFailed:
+				flips[r.Name].Failed++
+			}
+		}
+	}
+	for _, f := range flips {
+		if f.Passed > 0 && f.Failed > 0 {
+			c.Flaky = append(c.Flaky, *f)
+		}
+	}
+	sort.Slice(c.Flaky, func(i, j int) bool { return c.Flaky[i].Name < c.Flaky[j].Name })
+
+	if len(runs) == 0 {
+		return c
+	}
+	baselineSpecs := map[string]SpecResult{}
+	for _, r := range baseline.Specs {
+		baselineSpecs[r.Name] = r
+	}
+	for _, r := range runs[len(runs)-1].Specs {
+		base, found := baselineSpecs[r.Name]
+		if r.State == SpecFailed && (!found || base.State != SpecFailed) {
+			c.NewFailures = append(c.NewFailures, r)
+		}
+		if found && r.State == SpecPassed && base.State == SpecPassed &&
+			r.Time > base.Time*opts.Ratio && r.Time-base.Time > opts.MinDelta {
+			c.Regressions = append(c.Regressions, DurationRegression{Name: r.Name, Baseline: base.Time, Current: r.Time})
+		}
+	}
+	sort.Slice(c.NewFailures, func(i, j int) bool { return c.NewFailures[i].Name < c.NewFailures[j].Name })
+	sort.Slice(c.Regressions, func(i, j int) bool { return c.Regressions[i].Name < c.Regressions[j].Name })
+	return c
+}
+
+// Print prints the comparison as text
+func (c Comparison) Print(w io.Writer) {
+	fmt.Fprintf(w, "New failures (%d):\n", len(c.NewFailures))
+	for _, r := range c.NewFailures {
+		fmt.Fprintf(w, "  %s\n", r.Name)
+	}
+	fmt.Fprintf(w, "Flaky specs (%d):\n", len(c.Flaky))
+	for _, f := range c.Flaky {
+		fmt.Fprintf(w, "  %s (passed %d, failed %d)\n", f.Name, f.Passed, f.Failed)
+	}
+	fmt.Fprintf(w, "Duration regressions (%d):\n", len(c.Regressions))
+	for _, r := range c.Regressions {
+		fmt.Fprintf(w, "  %s (%.1fs -> %.1fs)\n", r.Name, r.Baseline, r.Current)
+	}
+}
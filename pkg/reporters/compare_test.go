@@ -0,0 +1,106 @@
+// Copyright (c) 2021 Red Hat, Inc.
+// Copyright Contributors to the Open Cluster Management project
+
+package reporters
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func summaryOf(specs ...SpecResult) *Summary {
+	s := NewSummary("suite")
+	for _, r := range specs {
+		s.Add(r)
+	}
+	return s
+}
+
+func TestCompare(t *testing.T) {
+	baseline := summaryOf(
+		SpecResult{Name: "stable", State: SpecPassed, Time: 100},
+		SpecResult{Name: "flaky", State: SpecPassed, Time: 10},
+		SpecResult{Name: "slow", State: SpecPassed, Time: 100},
+		SpecResult{Name: "broken", State: SpecFailed, Time: 10},
+	)
+	runs := []*Summary{
+		summaryOf(
+			SpecResult{Name: "stable", State: SpecPassed, Time: 100},
+			SpecResult{Name: "flaky", State: SpecFailed, Time: 10},
+			SpecResult{Name: "slow", State: SpecPassed, Time: 120},
+			SpecResult{Name: "broken", State: SpecFailed, Time: 10},
+		),
+		summaryOf(
+			SpecResult{Name: "stable", State: SpecPassed, Time: 110},
+			SpecResult{Name: "flaky", State: SpecPassed, Time: 10},
+			SpecResult{Name: "slow", State: SpecPassed, Time: 400},
+			SpecResult{Name: "broken", State: SpecFailed, Time: 10},
+			SpecResult{Name: "new", State: SpecFailed, Time: 10},
+		),
+	}
+
+	c := Compare(baseline, runs, CompareOptions{Ratio: 1.5, MinDelta: 60})
+	assert.Equal(t, []FlakySpec{{Name: "flaky", Passed: 2, Failed: 1}}, c.Flaky)
+	assert.Equal(t, []DurationRegression{{Name: "slow", Baseline: 100, Current: 400}}, c.Regressions)
+	require.Len(t, c.NewFailures, 1)
+	assert.Equal(t, "new", c.NewFailures[0].Name)
+}
+
+func TestLoadSummaryAndHistory(t *testing.T) {
+	dir, err := ioutil.TempDir("", "compare")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	report := filepath.Join(dir, "results.xml")
+	require.NoError(t, ioutil.WriteFile(report, []byte(`<testsuite name="suite" tests="2" failures="1" time="30">
+  <testcase name="[P1][Sev1][Observability][Stable] Should pass (alert/g0)" time="10"></testcase>
+  <testcase name="[P2][Sev2][Observability][Stable] Should fail (addon/g0)" time="20"><failure type="Failure">boom</failure></testcase>
+</testsuite>`), 0644))
+	s, err := LoadSummary(report)
+	require.NoError(t, err)
+	assert.Equal(t, 2, s.Total)
+	assert.Equal(t, 1, s.Failed)
+	assert.Equal(t, "alert", s.Specs[0].Area)
+	assert.Equal(t, "boom", s.Specs[1].Failure)
+
+	summaryFile := filepath.Join(dir, "results.json")
+	require.NoError(t, s.Write(summaryFile))
+	fromJSON, err := LoadSummary(summaryFile)
+	require.NoError(t, err)
+	assert.Equal(t, s, fromJSON)
+
+	historyFile := filepath.Join(dir, "history", "history.json")
+	h, err := LoadHistory(historyFile)
+	require.NoError(t, err)
+	h.Add("run-1", s)
+	h.Add("run-2", s)
+	h.Add("run-1", fromJSON)
+
+	// the same results have the same ID, the next run reported to the same file has another one
+	id, err := RunID(s)
+	require.NoError(t, err)
+	fromJSONID, err := RunID(fromJSON)
+	require.NoError(t, err)
+	assert.Equal(t, id, fromJSONID)
+	h.Add(id, s)
+	h.Add(fromJSONID, fromJSON)
+	next := NewSummary("suite")
+	next.Add(SpecResult{Name: "[P1][Sev1][Observability][Stable] Should pass (alert/g0)", State: SpecPassed})
+	nextID, err := RunID(next)
+	require.NoError(t, err)
+	assert.NotEqual(t, id, nextID)
+	h.Add(nextID, next)
+
+	require.NoError(t, h.Save(historyFile))
+	h, err = LoadHistory(historyFile)
+	require.NoError(t, err)
+	assert.Len(t, h.Runs, 4)
+	assert.Len(t, h.Summaries(1), 1)
+	assert.Equal(t, next, h.Summaries(1)[0])
+	assert.Len(t, h.Summaries(0), 4)
+}
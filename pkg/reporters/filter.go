@@ -0,0 +1,94 @@
+// Copyright (c) 2021 Red Hat, Inc.
+// Copyright Contributors to the Open Cluster Management project
+
+package reporters
+
+import (
+	"fmt"
+	"strings"
+)
+
+// SpecFilter selects the specs by the metadata in the spec names, an empty field selects all values,
+// e.g. SpecFilter{Priorities: []string{"P1"}, Tiers: []string{TierStable}, Areas: []string{"alert", "addon"}}
+// selects all P1 Stable specs in the alert and addon areas
+type SpecFilter struct {
+	Priorities []string
+	Severities []string
+	Tiers      []string
+	Areas      []string
+	SkipTiers  []string
+}
+
+// NewSpecFilter returns the filter of the comma separated values, e.g. the values of the suite flags
+func NewSpecFilter(priorities, severities, tiers, areas, skipTiers string) SpecFilter {
+	return SpecFilter{
+		Priorities: splitList(priorities),
+		Severities: splitList(severities),
+		Tiers:      splitList(tiers),
+		Areas:      splitList(areas),
+		SkipTiers:  splitList(skipTiers),
+	}
+}
+
+// IsEmpty returns true if the filter selects all specs
+func (f SpecFilter) IsEmpty() bool {
+	return len(f.Priorities) == 0 && len(f.Severities) == 0 && len(f.Tiers) == 0 && len(f.Areas) == 0 &&
+		len(f.SkipTiers) == 0
+}
+
+// Match returns true if the filter selects the spec of the metadata, the specs without the filtered
+// field are not selected
+func (f SpecFilter) Match(m SpecMetadata) bool {
+	return matchList(f.Priorities, m.Priority) && matchList(f.Severities, m.Severity) &&
+		matchList(f.Tiers, m.Tier) && matchList(f.Areas, m.Area) && f.AllowsTier(m.Tier)
+}
+
+// AllowsTier returns true if the specs of the tier can be selected, e.g. the install step only creates the
+// resources of the Integration specs if the tier is allowed
+func (f SpecFilter) AllowsTier(tier string) bool {
+	if containsFold(f.SkipTiers, tier) {
+		return false
+	}
+	return len(f.Tiers) == 0 || containsFold(f.Tiers, tier)
+}
+
+func (f SpecFilter) String() string {
+	fields := []string{}
+	add := func(name string, values []string) {
+		if len(values) > 0 {
+			fields = append(fields, fmt.Sprintf("%s=%s", name, strings.Join(values, ",")))
+		}
+	}
+	add("priority", f.Priorities)
+	add("severity", f.Severities)
+	add("tier", f.Tiers)
+	add("area", f.Areas)
+	add("skip-tier", f.SkipTiers)
+	return strings.Join(fields, " ")
+}
+
+func matchList(list []string, value string) bool {
+	return len(list) == 0 || containsFold(list, value)
+}
+
+func containsFold(list []string, value string) bool {
+	if value == "" {
+		return false
+	}
+	for _, item := range list {
+		if strings.EqualFold(item, value) {
+			return true
+		}
+	}
+	return false
+}
+
+func splitList(value string) []string {
+	list := []string{}
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+	return list
+}
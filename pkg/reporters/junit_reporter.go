@@ -0,0 +1,213 @@
+// Copyright (c) 2021 Red Hat, Inc.
+// Copyright Contributors to the Open Cluster Management project
+
+package reporters
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/onsi/ginkgo/config"
+	ginkgoreporters "github.com/onsi/ginkgo/reporters"
+	"github.com/onsi/ginkgo/types"
+)
+
+// JUnitTestSuite is the same as the ginkgo one with the properties added
+type JUnitTestSuite struct {
+	XMLName    xml.Name         `xml:"testsuite"`
+	Properties *JUnitProperties `xml:"properties,omitempty"`
+	TestCases  []JUnitTestCase  `xml:"testcase"`
+	Name       string           `xml:"name,attr"`
+	Tests      int              `xml:"tests,attr"`
+	Failures   int              `xml:"failures,attr"`
+	Errors     int              `xml:"errors,attr"`
+	Time       float64          `xml:"time,attr"`
+}
+
+type JUnitTestCase struct {
+	Name           string                               `xml:"name,attr"`
+	ClassName      string                               `xml:"classname,attr"`
+	Properties     *JUnitProperties                     `xml:"properties,omitempty"`
+	FailureMessage *ginkgoreporters.JUnitFailureMessage `xml:"failure,omitempty"`
+	Skipped        *ginkgoreporters.JUnitSkipped        `xml:"skipped,omitempty"`
+	Time           float64                              `xml:"time,attr"`
+	SystemOut      string                               `xml:"system-out,omitempty"`
+}
+
+type JUnitProperties struct {
+	Properties []JUnitProperty `xml:"property"`
+}
+
+type JUnitProperty struct {
+	Name  string `xml:"name,attr"`
+	Value string `xml:"value,attr"`
+}
+
+var (
+	propertiesLock    sync.Mutex
+	pendingProperties []JUnitProperty
+)
+
+// RecordProperty attaches a property to the running spec, or to the suite when no spec is running
+func RecordProperty(name string, value interface{}) {
+	propertiesLock.Lock()
+	defer propertiesLock.Unlock()
+	pendingProperties = append(pendingProperties, JUnitProperty{Name: name, Value: fmt.Sprint(value)})
+}
+
+func popProperties() []JUnitProperty {
+	propertiesLock.Lock()
+	defer propertiesLock.Unlock()
+	props := pendingProperties
+	pendingProperties = nil
+	return props
+}
+
+// recordedCase is what is recorded for a test case of the ginkgo report
+type recordedCase struct {
+	properties []JUnitProperty
+	waits      []StepWait
+}
+
+// JUnitReporter is the ginkgo junit reporter adding the properties recorded by the specs to the report
+// and writing the JSON summary next to it
+type JUnitReporter struct {
+	*ginkgoreporters.JUnitReporter
+	filename        string
+	suiteProperties []JUnitProperty
+	// in the order ginkgo adds the test cases
+	cases []recordedCase
+}
+
+// NewJUnitReporter creates a new JUnit XML reporter. The XML will be stored in the passed in filename.
+func NewJUnitReporter(filename string) *JUnitReporter {
+	return &JUnitReporter{
+		JUnitReporter: ginkgoreporters.NewJUnitReporter(filename),
+		filename:      filename,
+	}
+}
+
+func (reporter *JUnitReporter) SpecWillRun(specSummary *types.SpecSummary) {
+	// properties recorded between specs belong to the suite
+	reporter.suiteProperties = append(reporter.suiteProperties, popProperties()...)
+	popWaits()
+	reporter.JUnitReporter.SpecWillRun(specSummary)
+}
+
+func (reporter *JUnitReporter) BeforeSuiteDidRun(setupSummary *types.SetupSummary) {
+	reporter.recordSetup(setupSummary)
+	reporter.JUnitReporter.BeforeSuiteDidRun(setupSummary)
+}
+
+func (reporter *JUnitReporter) AfterSuiteDidRun(setupSummary *types.SetupSummary) {
+	reporter.recordSetup(setupSummary)
+	reporter.JUnitReporter.AfterSuiteDidRun(setupSummary)
+}
+
+// recordSetup records a test case for the setup which ginkgo only reports when it is not passed
+func (reporter *JUnitReporter) recordSetup(setupSummary *types.SetupSummary) {
+	props := popProperties()
+	if setupSummary.State == types.SpecStatePassed {
+		reporter.suiteProperties = append(reporter.suiteProperties, props...)
+		return
+	}
+	reporter.cases = append(reporter.cases, recordedCase{properties: props, waits: popWaits()})
+}
+
+func (reporter *JUnitReporter) SpecDidComplete(specSummary *types.SpecSummary) {
+	reporter.cases = append(reporter.cases, recordedCase{properties: popProperties(), waits: popWaits()})
+	reporter.JUnitReporter.SpecDidComplete(specSummary)
+}
+
+func (reporter *JUnitReporter) SpecSuiteDidEnd(summary *types.SuiteSummary) {
+	reporter.JUnitReporter.SpecSuiteDidEnd(summary)
+
+	filename := reporter.filename
+	if config.DefaultReporterConfig.ReportFile != "" {
+		filename = config.DefaultReporterConfig.ReportFile
+	}
+	if err := reporter.amendReport(filename, append(reporter.suiteProperties, popProperties()...)); err != nil {
+		fmt.Fprintf(os.Stderr, "\nFailed to add the properties to the JUnit report:\n\t%s", err.Error())
+	}
+}
+
+// amendReport adds the recorded properties to the report written by ginkgo, names the setup test cases
+// by the spec metadata and writes the JSON summary with the recorded waits
+func (reporter *JUnitReporter) amendReport(filename string, suiteProperties []JUnitProperty) error {
+	data, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return err
+	}
+	suite := JUnitTestSuite{}
+	if err := xml.Unmarshal(data, &suite); err != nil {
+		return err
+	}
+	if len(suite.TestCases) != len(reporter.cases) {
+		return fmt.Errorf("the report has %d test cases, %d are recorded", len(suite.TestCases), len(reporter.cases))
+	}
+
+	suite.Properties = newJUnitProperties(suiteProperties)
+	s := NewSummary(suite.Name)
+	s.Time = suite.Time
+	for i := range suite.TestCases {
+		testCase := &suite.TestCases[i]
+		switch testCase.Name {
+		case "BeforeSuite":
+			testCase.Name = BeforeSuiteName
+		case "AfterSuite":
+			testCase.Name = AfterSuiteName
+		}
+		testCase.Properties = newJUnitProperties(specProperties(testCase.Name, reporter.cases[i].properties))
+		result := specResult(*testCase)
+		result.Waits = reporter.cases[i].waits
+		s.Add(result)
+	}
+	if err := s.Write(summaryFilename(filename)); err != nil {
+		return err
+	}
+
+	data, err = xml.MarshalIndent(suite, "  ", "    ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(filename, append([]byte(xml.Header), data...), 0644)
+}
+
+// specProperties returns the properties of the metadata in the spec name and the recorded properties
+func specProperties(name string, recorded []JUnitProperty) []JUnitProperty {
+	return append(ParseSpecMetadata(name).Properties(), recorded...)
+}
+
+func newJUnitProperties(props []JUnitProperty) *JUnitProperties {
+	if len(props) == 0 {
+		return nil
+	}
+	return &JUnitProperties{Properties: props}
+}
+
+// specResult returns the result of the test case in the JSON summary
+func specResult(testCase JUnitTestCase) SpecResult {
+	result := SpecResult{
+		Name:         testCase.Name,
+		State:        SpecPassed,
+		Time:         testCase.Time,
+		SpecMetadata: ParseSpecMetadata(testCase.Name),
+	}
+	if testCase.FailureMessage != nil {
+		result.State = SpecFailed
+		result.Failure = testCase.FailureMessage.Message
+	} else if testCase.Skipped != nil {
+		result.State = SpecSkipped
+	}
+	return result
+}
+
+// summaryFilename returns the file of the JSON summary next to the junit report
+func summaryFilename(filename string) string {
+	return strings.TrimSuffix(filename, filepath.Ext(filename)) + ".json"
+}
@@ -0,0 +1,65 @@
+// Copyright (c) 2021 Red Hat, Inc.
+// Copyright Contributors to the Open Cluster Management project
+
+package reporters
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/onsi/ginkgo/config"
+	"github.com/onsi/ginkgo/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJUnitReporter(t *testing.T) {
+	popWaits()
+	popProperties()
+
+	dir, err := ioutil.TempDir("", "junit")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	filename := filepath.Join(dir, "results.xml")
+
+	reporter := NewJUnitReporter(filename)
+	reporter.SpecSuiteWillBegin(config.GinkgoConfig, &types.SuiteSummary{SuiteDescription: "suite"})
+	RecordProperty("hub", "local-cluster")
+	reporter.BeforeSuiteDidRun(&types.SetupSummary{State: types.SpecStatePassed})
+
+	spec := &types.SpecSummary{
+		ComponentTexts: []string{"suite", "[P1][Sev1][Observability][Stable] Should have metrics (metrics/g0)"},
+		State:          types.SpecStatePassed,
+		RunTime:        time.Second,
+	}
+	reporter.SpecWillRun(spec)
+	RecordProperty("ingestion-latency-p50/cluster1", 2.5)
+	NewWait("Waiting for metrics").Done(true)
+	reporter.SpecDidComplete(spec)
+
+	RecordProperty("uninstall", "failed")
+	reporter.AfterSuiteDidRun(&types.SetupSummary{State: types.SpecStateFailed})
+	reporter.SpecSuiteDidEnd(&types.SuiteSummary{SuiteDescription: "suite", NumberOfSpecsThatWillBeRun: 1, NumberOfFailedSpecs: 0})
+
+	s, err := LoadSummary(filename)
+	require.NoError(t, err)
+	assert.Equal(t, 1, s.Passed)
+	assert.Equal(t, 1, s.Failed)
+	data, err := ioutil.ReadFile(filename)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `<property name="hub" value="local-cluster"></property>`)
+	assert.Contains(t, string(data), `<property name="ingestion-latency-p50/cluster1" value="2.5"></property>`)
+	assert.Contains(t, string(data), `<property name="area" value="metrics"></property>`)
+	assert.Contains(t, string(data), `<property name="uninstall" value="failed"></property>`)
+	assert.Contains(t, string(data), `name="`+AfterSuiteName+`"`)
+
+	s, err = LoadSummary(summaryFilename(filename))
+	require.NoError(t, err)
+	require.Len(t, s.Specs, 2)
+	require.Len(t, s.Specs[0].Waits, 1)
+	assert.Equal(t, "Waiting for metrics", s.Specs[0].Waits[0].Step)
+	assert.Equal(t, AfterSuiteName, s.Specs[1].Name)
+}
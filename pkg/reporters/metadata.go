@@ -0,0 +1,93 @@
+// Copyright (c) 2021 Red Hat, Inc.
+// Copyright Contributors to the Open Cluster Management project
+
+package reporters
+
+import (
+	"regexp"
+	"strings"
+)
+
+const (
+	// the names of the suite setup and teardown nodes in the report
+	BeforeSuiteName = "Observability: [P1][Sev1][Observability] Cannot enable observability service successfully"
+	AfterSuiteName  = "Observability: [P1][Sev1][Observability] Cannot uninstall observability service completely"
+)
+
+var (
+	specTagRegexp   = regexp.MustCompile(`\[([^\[\]]+)\]`)
+	specGroupRegexp = regexp.MustCompile(`\(([\w-]+)/([\w-]+)\)`)
+	priorityRegexp  = regexp.MustCompile(`^P\d+$`)
+	severityRegexp  = regexp.MustCompile(`^Sev\d+$`)
+)
+
+// the stability tiers in the spec names
+const (
+	TierStable      = "Stable"
+	TierIntegration = "Integration"
+	TierSmoke       = "Smoke"
+	TierCanary      = "Canary"
+)
+
+var specTiers = []string{TierStable, TierIntegration, TierSmoke, TierCanary}
+
+// SpecMetadata is the metadata encoded in the spec names, e.g.
+// [P1][Sev1][Observability][Stable] Should have alerts (alert/g0)
+type SpecMetadata struct {
+	Priority string   `json:"priority,omitempty"`
+	Severity string   `json:"severity,omitempty"`
+	Tier     string   `json:"tier,omitempty"`
+	Tags     []string `json:"tags,omitempty"`
+	Area     string   `json:"area,omitempty"`
+	Group    string   `json:"group,omitempty"`
+}
+
+// ParseSpecMetadata parses the bracketed tags and the (area/group) suffix of the spec name, the last
+// (area/group) wins for the specs nested in a labeled container
+func ParseSpecMetadata(name string) SpecMetadata {
+	m := SpecMetadata{}
+	for _, match := range specTagRegexp.FindAllStringSubmatch(name, -1) {
+		tag := strings.TrimSpace(match[1])
+		switch {
+		case m.Priority == "" && priorityRegexp.MatchString(tag):
+			m.Priority = tag
+		case m.Severity == "" && severityRegexp.MatchString(tag):
+			m.Severity = tag
+		case m.Tier == "" && isSpecTier(tag):
+			m.Tier = tag
+		default:
+			m.Tags = append(m.Tags, tag)
+		}
+	}
+	if matches := specGroupRegexp.FindAllStringSubmatch(name, -1); len(matches) > 0 {
+		match := matches[len(matches)-1]
+		m.Area, m.Group = match[1], match[2]
+	}
+	return m
+}
+
+func isSpecTier(tag string) bool {
+	for _, tier := range specTiers {
+		if strings.EqualFold(tier, tag) {
+			return true
+		}
+	}
+	return false
+}
+
+// Properties returns the metadata as the junit properties
+func (m SpecMetadata) Properties() []JUnitProperty {
+	props := []JUnitProperty{}
+	add := func(name, value string) {
+		if value != "" {
+			props = append(props, JUnitProperty{Name: name, Value: value})
+		}
+	}
+	add("priority", m.Priority)
+	add("severity", m.Severity)
+	add("tier", m.Tier)
+	add("tags", strings.Join(m.Tags, ","))
+	add("area", m.Area)
+	add("group", m.Group)
+	return props
+}
@@ -0,0 +1,85 @@
+// Copyright (c) 2021 Red Hat, Inc.
+// Copyright Contributors to the Open Cluster Management project
+
+package reporters
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseSpecMetadata(t *testing.T) {
+	m := ParseSpecMetadata("Observability: [P1][Sev1][Observability][Stable] Should have alerts (alert/g0)")
+	assert.Equal(t, SpecMetadata{
+		Priority: "P1",
+		Severity: "Sev1",
+		Tier:     "Stable",
+		Tags:     []string{"Observability"},
+		Area:     "alert",
+		Group:    "g0",
+	}, m)
+	assert.Equal(t, []JUnitProperty{
+		{Name: "priority", Value: "P1"},
+		{Name: "severity", Value: "Sev1"},
+		{Name: "tier", Value: "Stable"},
+		{Name: "tags", Value: "Observability"},
+		{Name: "area", Value: "alert"},
+		{Name: "group", Value: "g0"},
+	}, m.Properties())
+
+	m = ParseSpecMetadata(BeforeSuiteName)
+	assert.Equal(t, "P1", m.Priority)
+	assert.Equal(t, "", m.Area)
+
+	assert.Equal(t, []JUnitProperty{{Name: "tier", Value: "Stable"}}, ParseSpecMetadata("[Stable] no area").Properties())
+}
+
+func TestSummary(t *testing.T) {
+	s := NewSummary("suite")
+	s.Add(SpecResult{Name: "a", State: SpecPassed, SpecMetadata: SpecMetadata{Priority: "P1", Area: "alert"}})
+	s.Add(SpecResult{Name: "b", State: SpecFailed, SpecMetadata: SpecMetadata{Priority: "P1", Area: "addon"}})
+	s.Add(SpecResult{Name: "c", State: SpecSkipped})
+
+	assert.Equal(t, SummaryCount{Total: 3, Passed: 1, Failed: 1, Skipped: 1}, s.SummaryCount)
+	assert.Equal(t, &SummaryCount{Total: 2, Passed: 1, Failed: 1}, s.Priorities["P1"])
+	assert.Equal(t, &SummaryCount{Total: 1, Failed: 1}, s.Areas["addon"])
+	assert.Equal(t, &SummaryCount{Total: 1, Skipped: 1}, s.Areas[unknownKey])
+	assert.Equal(t, &SummaryCount{Total: 1, Skipped: 1}, s.Priorities[unknownKey])
+	assert.Equal(t, "results.json", summaryFilename("results.xml"))
+}
+
+func TestParseNestedSpecMetadata(t *testing.T) {
+	// the specs nested in a labeled container
+	m := ParseSpecMetadata("Observability: [P2][Sev2][Observability] Disable the Observability (addon/g0) - [Stable] Disable observability")
+	assert.Equal(t, "P2", m.Priority)
+	assert.Equal(t, TierStable, m.Tier)
+	assert.Equal(t, "addon", m.Area)
+}
+
+func TestSpecFilter(t *testing.T) {
+	stableAlert := SpecMetadata{Priority: "P1", Severity: "Sev1", Tier: TierStable, Area: "alert"}
+	integrationAddon := SpecMetadata{Priority: "P1", Severity: "Sev1", Tier: TierIntegration, Area: "addon"}
+	stableGrafana := SpecMetadata{Priority: "P2", Severity: "Sev2", Tier: TierStable, Area: "grafana"}
+
+	f := NewSpecFilter("", "", "", "", "")
+	assert.True(t, f.IsEmpty())
+	assert.True(t, f.Match(stableAlert))
+	assert.True(t, f.Match(SpecMetadata{}))
+
+	f = NewSpecFilter("p1", "", "Stable", "alert, addon", "")
+	assert.False(t, f.IsEmpty())
+	assert.True(t, f.Match(stableAlert))
+	assert.False(t, f.Match(integrationAddon))
+	assert.False(t, f.Match(stableGrafana))
+	assert.False(t, f.Match(SpecMetadata{}))
+	assert.False(t, f.AllowsTier(TierIntegration))
+	assert.Equal(t, "priority=p1 tier=Stable area=alert,addon", f.String())
+
+	f = NewSpecFilter("", "", "", "", "Integration")
+	assert.True(t, f.Match(stableAlert))
+	assert.False(t, f.Match(integrationAddon))
+	assert.True(t, f.Match(SpecMetadata{}))
+	assert.False(t, f.AllowsTier(TierIntegration))
+	assert.True(t, f.AllowsTier(TierStable))
+}
@@ -0,0 +1,109 @@
+// Copyright (c) 2021 Red Hat, Inc.
+// Copyright Contributors to the Open Cluster Management project
+
+package reporters
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+)
+
+const (
+	SpecPassed  = "passed"
+	SpecFailed  = "failed"
+	SpecSkipped = "skipped"
+
+	// the key of the specs without area or priority in the summary
+	unknownKey = "unknown"
+)
+
+// SpecResult is the result of a spec in the JSON summary
+type SpecResult struct {
+	Name  string  `json:"name"`
+	State string  `json:"state"`
+	Time  float64 `json:"time"`
+	SpecMetadata
+	Failure string     `json:"failure,omitempty"`
+	Waits   []StepWait `json:"waits,omitempty"`
+}
+
+// SummaryCount is the number of the specs in each state
+type SummaryCount struct {
+	Total   int `json:"total"`
+	Passed  int `json:"passed"`
+	Failed  int `json:"failed"`
+	Skipped int `json:"skipped"`
+}
+
+func (c *SummaryCount) add(state string) {
+	c.Total++
+	switch state {
+	case SpecPassed:
+		c.Passed++
+	case SpecFailed:
+		c.Failed++
+	case SpecSkipped:
+		c.Skipped++
+	}
+}
+
+// Summary is the JSON summary of the suite grouped by area and priority, with the wait time of the By
+// steps over all the specs
+type Summary struct {
+	Suite string  `json:"suite"`
+	Time  float64 `json:"time"`
+	SummaryCount
+	Areas      map[string]*SummaryCount `json:"areas"`
+	Priorities map[string]*SummaryCount `json:"priorities"`
+	Waits      map[string]*WaitStats    `json:"waits"`
+	Specs      []SpecResult             `json:"specs"`
+}
+
+// NewSummary returns an empty summary of the suite
+func NewSummary(suite string) *Summary {
+	return &Summary{
+		Suite:      suite,
+		Areas:      map[string]*SummaryCount{},
+		Priorities: map[string]*SummaryCount{},
+		Waits:      map[string]*WaitStats{},
+		Specs:      []SpecResult{},
+	}
+}
+
+// Add adds the result of a spec to the summary
+func (s *Summary) Add(r SpecResult) {
+	s.Specs = append(s.Specs, r)
+	s.SummaryCount.add(r.State)
+	addToGroup(s.Areas, r.Area, r.State)
+	addToGroup(s.Priorities, r.Priority, r.State)
+	for _, w := range r.Waits {
+		if s.Waits[w.Step] == nil {
+			s.Waits[w.Step] = &WaitStats{}
+		}
+		s.Waits[w.Step].add(w)
+	}
+}
+
+func addToGroup(groups map[string]*SummaryCount, key, state string) {
+	if key == "" {
+		key = unknownKey
+	}
+	if groups[key] == nil {
+		groups[key] = &SummaryCount{}
+	}
+	groups[key].add(state)
+}
+
+// Write writes the summary to the file as JSON
+func (s *Summary) Write(file string) error {
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(file), os.ModePerm); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(file, data, 0644)
+}
@@ -0,0 +1,133 @@
+// Copyright (c) 2021 Red Hat, Inc.
+// Copyright Contributors to the Open Cluster Management project
+
+package reporters
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"sync"
+	"time"
+)
+
+// StepWait is the time a By step waited in an Eventually block
+type StepWait struct {
+	Step      string  `json:"step"`
+	Time      float64 `json:"time"`
+	Polls     int     `json:"polls"`
+	LastError string  `json:"lastError,omitempty"`
+	Succeeded bool    `json:"succeeded"`
+}
+
+func (w StepWait) String() string {
+	s := fmt.Sprintf("%s: waited %.1fs in %d polls", w.Step, w.Time, w.Polls)
+	if !w.Succeeded {
+		s += " and timed out"
+	}
+	if w.LastError != "" {
+		s += ", last error: " + w.LastError
+	}
+	return s
+}
+
+// WaitStats is the wait time of a By step over all the specs
+type WaitStats struct {
+	Count    int     `json:"count"`
+	Failed   int     `json:"failed"`
+	Total    float64 `json:"total"`
+	Average  float64 `json:"average"`
+	Max      float64 `json:"max"`
+	Polls    int     `json:"polls"`
+	AvgPolls float64 `json:"avgPolls"`
+}
+
+func (s *WaitStats) add(w StepWait) {
+	s.Count++
+	if !w.Succeeded {
+		s.Failed++
+	}
+	s.Total += w.Time
+	s.Max = math.Max(s.Max, w.Time)
+	s.Polls += w.Polls
+	s.Average = s.Total / float64(s.Count)
+	s.AvgPolls = float64(s.Polls) / float64(s.Count)
+}
+
+var (
+	waitsLock    sync.Mutex
+	pendingWaits []StepWait
+)
+
+func popWaits() []StepWait {
+	waitsLock.Lock()
+	defer waitsLock.Unlock()
+	waits := pendingWaits
+	pendingWaits = nil
+	return waits
+}
+
+// Wait records the polls of an Eventually block of a By step, e.g.
+//
+//	w := reporters.NewWait("Wait for thanos rule pods are restarted and ready")
+//	Eventually(w.Wrap(fn), timeout, interval).Should(Succeed())
+//	w.Done(true)
+type Wait struct {
+	mu    sync.Mutex
+	wait  StepWait
+	start time.Time
+}
+
+// NewWait starts the wait of the step
+func NewWait(step string) *Wait {
+	return &Wait{wait: StepWait{Step: step}, start: time.Now()}
+}
+
+// Poll records a poll and its error
+func (w *Wait) Poll(err error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.wait.Polls++
+	if err != nil {
+		w.wait.LastError = err.Error()
+	}
+}
+
+// Wrap returns the function polled by Eventually with each call recorded, a non-nil error in the last
+// return value or a false first return value is recorded as the error of the poll
+func (w *Wait) Wrap(fn interface{}) interface{} {
+	v := reflect.ValueOf(fn)
+	if v.Kind() != reflect.Func {
+		return fn
+	}
+	errorType := reflect.TypeOf((*error)(nil)).Elem()
+	return reflect.MakeFunc(v.Type(), func(args []reflect.Value) []reflect.Value {
+		out := v.Call(args)
+		var err error
+		if n := len(out); n > 0 {
+			last := out[n-1]
+			if last.Type() == errorType && !last.IsNil() {
+				err = last.Interface().(error)
+			} else if out[0].Kind() == reflect.Bool && !out[0].Bool() {
+				err = fmt.Errorf("returned false")
+			}
+		}
+		w.Poll(err)
+		return out
+	}).Interface()
+}
+
+// Done stops the wait and attaches it to the running spec in the report
+func (w *Wait) Done(succeeded bool) StepWait {
+	w.mu.Lock()
+	w.wait.Time = math.Trunc(time.Since(w.start).Seconds()*1000) / 1000
+	w.wait.Succeeded = succeeded
+	wait := w.wait
+	w.mu.Unlock()
+
+	RecordProperty("wait", wait)
+	waitsLock.Lock()
+	defer waitsLock.Unlock()
+	pendingWaits = append(pendingWaits, wait)
+	return wait
+}
@@ -0,0 +1,53 @@
+// Copyright (c) 2021 Red Hat, Inc.
+// Copyright Contributors to the Open Cluster Management project
+
+package reporters
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWait(t *testing.T) {
+	popWaits()
+	popProperties()
+
+	polls := 0
+	w := NewWait("Waiting for pods ready")
+	fn := w.Wrap(func() error {
+		polls++
+		if polls < 3 {
+			return errors.New("not ready")
+		}
+		return nil
+	}).(func() error)
+	for fn() != nil {
+	}
+	w.Done(true)
+
+	b := NewWait("Waiting for metrics")
+	fnBool := b.Wrap(func() bool { return false }).(func() bool)
+	assert.False(t, fnBool())
+	b.Done(false)
+
+	waits := popWaits()
+	require.Len(t, waits, 2)
+	assert.Equal(t, 3, waits[0].Polls)
+	assert.Equal(t, "not ready", waits[0].LastError)
+	assert.True(t, waits[0].Succeeded)
+	assert.Equal(t, 1, waits[1].Polls)
+	assert.Equal(t, "returned false", waits[1].LastError)
+	assert.False(t, waits[1].Succeeded)
+	assert.Len(t, popProperties(), 2)
+
+	s := NewSummary("suite")
+	s.Add(SpecResult{Name: "a", State: SpecPassed, Waits: waits[:1]})
+	s.Add(SpecResult{Name: "b", State: SpecPassed, Waits: waits[:1]})
+	s.Add(SpecResult{Name: "c", State: SpecFailed, Waits: waits[1:]})
+	assert.Equal(t, 2, s.Waits["Waiting for pods ready"].Count)
+	assert.Equal(t, 3.0, s.Waits["Waiting for pods ready"].AvgPolls)
+	assert.Equal(t, 1, s.Waits["Waiting for metrics"].Failed)
+}
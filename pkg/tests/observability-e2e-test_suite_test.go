@@ -9,15 +9,18 @@ import (
 	"io/ioutil"
 	"math/rand"
 	"os"
+	"path/filepath"
 	"testing"
 	"time"
 
 	. "github.com/onsi/ginkgo"
-	"github.com/onsi/ginkgo/reporters"
+	"github.com/onsi/ginkgo/config"
 	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/types"
 	"gopkg.in/yaml.v2"
 	"k8s.io/klog"
 
+	"github.com/stolostron/observability-e2e-test/pkg/reporters"
 	"github.com/stolostron/observability-e2e-test/pkg/utils"
 )
 
@@ -38,6 +41,21 @@ var (
 	testUITimeout        time.Duration
 
 	testFailed = false
+
+	// specFilter selects the specs by the metadata in the spec names
+	specFilter reporters.SpecFilter
+
+	specPriorities, specSeverities, specTiers, specAreas, skipTiers string
+
+	// restartMonitor records the container restarts in all specs
+	restartMonitor *utils.RestartMonitor
+	// eventRecorder records the warning events with the running spec
+	eventRecorder *utils.EventRecorder
+	// logScanner scans the component logs since the spec started
+	logScanner    *utils.LogScanner
+	specStartedAt time.Time
+	// artifactCollector writes the diagnostics of the failed specs next to the junit report
+	artifactCollector *utils.ArtifactCollector
 )
 
 const (
@@ -48,22 +66,22 @@ const (
 	MCO_CR_NAME         = "observability"
 	MCO_NAMESPACE       = "open-cluster-management-observability"
 	MCO_ADDON_NAMESPACE = "open-cluster-management-addon-observability"
-	MCO_LABEL           = "name=multicluster-observability-operator"
+	MCO_LABEL           = utils.MCO_OPERATOR_LABEL
 	MCO_LABEL_OWNER     = "owner=multicluster-observability-operator"
 
-	ALERTMANAGER_LABEL      = "app=multicluster-observability-alertmanager"
-	GRAFANA_LABEL           = "app=multicluster-observability-grafana"
-	OBSERVATORIUM_API_LABEL = "app.kubernetes.io/name=observatorium-api"
-	RBAC_QUERY_PROXY_LABEL  = "app=rbac-query-proxy"
-
-	THANOS_COMPACT_LABEL                  = "app.kubernetes.io/name=thanos-compact"
-	THANOS_STORE_LABEL                    = "app.kubernetes.io/name=thanos-store"
-	THANOS_RECEIVE_LABEL                  = "app.kubernetes.io/name=thanos-receive"
-	THANOS_RULE_LABEL                     = "app.kubernetes.io/name=thanos-rule"
-	THANOS_QUERY_LABEL                    = "app.kubernetes.io/name=thanos-query"
-	THANOS_QUERY_FRONTEND_LABEL           = "app.kubernetes.io/name=thanos-query-frontend"
-	THANOS_QUERY_FRONTEND_MEMCACHED_LABEL = "app.kubernetes.io/component=query-frontend-cache,app.kubernetes.io/name=memcached"
-	THANOS_STORE_MEMCACHED_LABEL          = "app.kubernetes.io/component=store-cache,app.kubernetes.io/name=memcached"
+	ALERTMANAGER_LABEL      = utils.AlertmanagerLabel
+	GRAFANA_LABEL           = utils.GrafanaLabel
+	OBSERVATORIUM_API_LABEL = utils.ObservatoriumAPILabel
+	RBAC_QUERY_PROXY_LABEL  = utils.RBACQueryProxyLabel
+
+	THANOS_COMPACT_LABEL                  = utils.ThanosCompactLabel
+	THANOS_STORE_LABEL                    = utils.ThanosStoreLabel
+	THANOS_RECEIVE_LABEL                  = utils.ThanosReceiveLabel
+	THANOS_RULE_LABEL                     = utils.ThanosRuleLabel
+	THANOS_QUERY_LABEL                    = utils.ThanosQueryLabel
+	THANOS_QUERY_FRONTEND_LABEL           = utils.ThanosQueryFrontendLabel
+	THANOS_QUERY_FRONTEND_MEMCACHED_LABEL = utils.ThanosQueryFrontendMemcachedLabel
+	THANOS_STORE_MEMCACHED_LABEL          = utils.ThanosStoreMemcachedLabel
 )
 
 var seededRand *rand.Rand = rand.New(
@@ -87,6 +105,11 @@ func init() {
 	flag.StringVar(&reportFile, "report-file", "results.xml", "Provide the path to where the junit results will be printed.")
 	flag.StringVar(&kubeconfig, "kubeconfig", "", "Location of the kubeconfig to use; defaults to KUBECONFIG if not set")
 	flag.StringVar(&optionsFile, "options", "", "Location of an \"options.yaml\" file to provide input for various tests")
+	flag.StringVar(&specPriorities, "priority", "", "Run the specs of the comma separated priorities only (e.g. -priority=\"P1,P2\").")
+	flag.StringVar(&specSeverities, "severity", "", "Run the specs of the comma separated severities only (e.g. -severity=\"Sev1\").")
+	flag.StringVar(&specTiers, "tier", "", "Run the specs of the comma separated tiers only (e.g. -tier=\"Stable\").")
+	flag.StringVar(&specAreas, "area", "", "Run the specs of the comma separated areas only (e.g. -area=\"alert,addon\").")
+	flag.StringVar(&skipTiers, "skip-tier", "", "Skip the specs of the comma separated tiers (e.g. -skip-tier=\"Integration\").")
 }
 
 func TestObservabilityE2E(t *testing.T) {
@@ -95,12 +118,150 @@ func TestObservabilityE2E(t *testing.T) {
 	RunSpecsWithDefaultAndCustomReporters(t, "Observability E2E Suite", []Reporter{junitReporter})
 }
 
+// reportDir returns the directory of the junit report, ginkgo's --reportFile overrides -report-file as in
+// the junit reporter
+func reportDir() string {
+	if config.DefaultReporterConfig.ReportFile != "" {
+		return filepath.Dir(config.DefaultReporterConfig.ReportFile)
+	}
+	return filepath.Dir(reportFile)
+}
+
+// timeout returns the timeout of the name in the catalog, overridden and scaled by the options
+func timeout(name utils.TimeoutName) time.Duration {
+	return utils.GetTimeout(testOptions, name)
+}
+
+// await is By(step) and a blocking wait, e.g. a watch-based waiter, with the wait of the step recorded in
+// the report
+func await(step string, wait func() error) {
+	By(step)
+	w := reporters.NewWait(step)
+	err := wait()
+	w.Poll(err)
+	w.Done(err == nil)
+	Expect(err).NotTo(HaveOccurred())
+}
+
+// waitAssertion is an Eventually assertion that attaches the wait time, polls and last error of the By step
+// to the spec in the report once the assertion completes
+type waitAssertion struct {
+	wait      *reporters.Wait
+	assertion AsyncAssertion
+}
+
+// eventually is By(step) and Eventually(fn, intervals...) with the wait of the step recorded in the report,
+// e.g. eventually("Waiting for MCO ready status", fn, timeout(utils.TimeoutMCOReady), EventuallyIntervalSecond*5).Should(Succeed())
+func eventually(step string, fn interface{}, intervals ...interface{}) waitAssertion {
+	By(step)
+	wait := reporters.NewWait(step)
+	return waitAssertion{wait: wait, assertion: Eventually(wait.Wrap(fn), intervals...)}
+}
+
+func (a waitAssertion) Should(matcher types.GomegaMatcher, optionalDescription ...interface{}) bool {
+	succeeded := false
+	// the failed assertion panics, the wait is still recorded
+	defer func() { a.wait.Done(succeeded) }()
+	succeeded = a.assertion.Should(matcher, optionalDescription...)
+	return succeeded
+}
+
+func (a waitAssertion) ShouldNot(matcher types.GomegaMatcher, optionalDescription ...interface{}) bool {
+	succeeded := false
+	defer func() { a.wait.Done(succeeded) }()
+	succeeded = a.assertion.ShouldNot(matcher, optionalDescription...)
+	return succeeded
+}
+
 var _ = BeforeSuite(func() {
 	initVars()
+	specFilter = reporters.NewSpecFilter(specPriorities, specSeverities, specTiers, specAreas, skipTiers)
+	if !specFilter.IsEmpty() {
+		klog.V(1).Infof("Run the specs selected by %s", specFilter)
+	}
+	artifactCollector = utils.NewArtifactCollector(testOptions, filepath.Join(reportDir(), "artifacts"))
 	installMCO()
+
+	restartMonitor = utils.NewRestartMonitor(testOptions)
+	Expect(restartMonitor.Start()).To(Succeed())
+
+	eventRecorder = utils.NewEventRecorder(testOptions)
+	Expect(eventRecorder.Start()).To(Succeed())
+
+	var err error
+	logScanner, err = utils.NewLogScanner(testOptions)
+	Expect(err).NotTo(HaveOccurred())
+})
+
+var _ = BeforeEach(func() {
+	// skip the specs not selected by the -priority, -severity, -tier, -area and -skip-tier flags before
+	// the setup of the specs runs
+	specStartedAt = time.Time{}
+	if !specFilter.Match(reporters.ParseSpecMetadata(CurrentGinkgoTestDescription().FullTestText)) {
+		Skip("not selected by " + specFilter.String())
+	}
+
+	specStartedAt = time.Now()
+	if eventRecorder != nil {
+		eventRecorder.SetSpec(CurrentGinkgoTestDescription().FullTestText)
+	}
+})
+
+// fail the spec if any container restarts unexpectedly, the specs declare the expected restarts with
+// restartMonitor.ExpectRestarts
+var _ = JustAfterEach(func() {
+	if restartMonitor != nil {
+		Expect(restartMonitor.Check()).To(Succeed())
+	}
+})
+
+// fail the spec if the component logs have panics, error bursts or known bad patterns since the spec
+// started, the specs declare the expected log lines with logScanner.Allow, and the specs breaking the
+// config on purpose declare the expected error bursts with logScanner.AllowBursts
+var _ = JustAfterEach(func() {
+	if logScanner == nil || specStartedAt.IsZero() {
+		return
+	}
+	Expect(logScanner.Check(specStartedAt)).NotTo(HaveOccurred())
+})
+
+// collect the artifacts of the failed specs before the AfterEach of the specs clean up, the JustAfterEach
+// of the specs and the checks above run first
+var _ = JustAfterEach(func() {
+	if artifactCollector != nil && CurrentGinkgoTestDescription().Failed {
+		if _, err := artifactCollector.Collect(CurrentGinkgoTestDescription().FullTestText); err != nil {
+			klog.Errorf("Failed to collect the artifacts due to %v", err)
+		}
+	}
+})
+
+var _ = AfterEach(func() {
+	if restartMonitor != nil {
+		restartMonitor.Reset()
+	}
+	if logScanner != nil {
+		logScanner.Reset()
+	}
+	if eventRecorder != nil && CurrentGinkgoTestDescription().Failed {
+		eventRecorder.PrintWarnings()
+	}
 })
 
 var _ = AfterSuite(func() {
+	if restartMonitor != nil {
+		restartMonitor.Stop()
+	}
+	if eventRecorder != nil {
+		eventRecorder.Stop()
+	}
+	if artifactCollector != nil {
+		if _, err := artifactCollector.Collect("AfterSuite"); err != nil {
+			klog.Errorf("Failed to collect the artifacts due to %v", err)
+		}
+		if err := artifactCollector.Archive(filepath.Join(reportDir(), "artifacts.tar.gz")); err != nil {
+			klog.Errorf("Failed to archive the artifacts due to %v", err)
+		}
+	}
 	if !testFailed {
 		uninstallMCO()
 	} else {
@@ -137,6 +298,7 @@ func initVars() {
 	}
 
 	testOptions = testOptionsContainer.Options
+	Expect(utils.ValidateTimeouts(testOptions)).To(Succeed())
 
 	// default Headless is `true`
 	// to disable, set Headless: false
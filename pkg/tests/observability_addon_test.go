@@ -6,6 +6,7 @@ package tests
 import (
 	"fmt"
 	"strings"
+	"time"
 
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
@@ -34,7 +35,17 @@ var _ = Describe("Observability:", func() {
 	})
 
 	Context("[P2][Sev2][Observability] Verify monitoring operator and deployment status when metrics collection disabled (addon/g0) -", func() {
-		clusterName := utils.GetManagedClusterName(testOptions)
+		var (
+			clusterName           string
+			disabledAt, enabledAt time.Time
+		)
+		BeforeEach(func() {
+			// the options are loaded after the tree is built
+			clusterName = utils.GetManagedClusterName(testOptions)
+			// the addon components log errors while the addon is disabled and enabled
+			logScanner.AllowBursts()
+		})
+
 		It("[Stable] Verify ObservabilityEndpoint operator deployment", func() {
 			By("Check enableMetrics is true")
 			enable, err := utils.GetMCOAddonSpecMetrics(testOptions)
@@ -49,20 +60,20 @@ var _ = Describe("Observability:", func() {
 						panic(err.Error())
 					}
 					return fmt.Sprintf("%T", mco.Object["status"])
-				}, EventuallyTimeoutMinute*5, EventuallyIntervalSecond*5).ShouldNot(Equal("nil"))
+				}, timeout(utils.TimeoutResourceReady), EventuallyIntervalSecond*5).ShouldNot(Equal("nil"))
 				Eventually(func() string {
 					mco, err := dynClient.Resource(utils.NewMCOAddonGVR()).Namespace(string(clusterName)).Get("observability-addon", metav1.GetOptions{})
 					if err != nil {
 						panic(err.Error())
 					}
 					return mco.Object["status"].(map[string]interface{})["conditions"].([]interface{})[0].(map[string]interface{})["message"].(string)
-				}, EventuallyTimeoutMinute*5, EventuallyIntervalSecond*5).Should(Equal("Metrics collector deployed and functional"))
+				}, timeout(utils.TimeoutResourceReady), EventuallyIntervalSecond*5).Should(Equal("Metrics collector deployed and functional"))
 			}
 
 			By("Check endpoint-operator and metrics-collector pods are created")
 			Eventually(func() error {
 				return utils.CheckMCOAddon(testOptions)
-			}, EventuallyTimeoutMinute*5, EventuallyIntervalSecond*5).Should(Succeed())
+			}, timeout(utils.TimeoutResourceReady), EventuallyIntervalSecond*5).Should(Succeed())
 		})
 
 		It("[Stable] Should have resource requirement defined in CR", func() {
@@ -81,22 +92,22 @@ var _ = Describe("Observability:", func() {
 			By("Check metrics-collector resource requirement")
 			Eventually(func() error {
 				return utils.CheckMCOAddonResources(testOptions)
-			}, EventuallyTimeoutMinute*5, EventuallyIntervalSecond*5).Should(Succeed())
+			}, timeout(utils.TimeoutReconcile), EventuallyIntervalSecond*5).Should(Succeed())
 		})
 
 		It("[Integration] Should not have the expected MCO addon pods when disable observabilityaddon", func() {
+			disabledAt = time.Now()
 			Eventually(func() error {
 				return utils.ModifyMCOAddonSpecMetrics(testOptions, false)
-			}, EventuallyTimeoutMinute*1, EventuallyIntervalSecond*5).Should(Succeed())
+			}, timeout(utils.TimeoutObjectUpdate), EventuallyIntervalSecond*5).Should(Succeed())
 
-			By("Waiting for MCO addon components scales to 0")
-			Eventually(func() error {
+			eventually("Waiting for MCO addon components scales to 0", func() error {
 				err, podList := utils.GetPodList(testOptions, false, MCO_ADDON_NAMESPACE, "component=metrics-collector")
 				if len(podList.Items) != 0 || err != nil {
 					return fmt.Errorf("Failed to disable observability addon")
 				}
 				return nil
-			}, EventuallyTimeoutMinute*5, EventuallyIntervalSecond*5).Should(Succeed())
+			}, timeout(utils.TimeoutAddonScaleDown), EventuallyIntervalSecond*5).Should(Succeed())
 
 			if clusterName != "" {
 				Eventually(func() error {
@@ -105,7 +116,7 @@ var _ = Describe("Observability:", func() {
 						return fmt.Errorf("observability-addon is not properly deleted for managed cluster %s", clusterName)
 					}
 					return nil
-				}, EventuallyTimeoutMinute*3, EventuallyIntervalSecond*5).Should(Equal(ManagedClusterAddOnMessage))
+				}, timeout(utils.TimeoutAddonStatus), EventuallyIntervalSecond*5).Should(Equal(ManagedClusterAddOnMessage))
 			}
 		})
 		// it takes Prometheus 5m to notice a metric is not available - https://github.com/prometheus/prometheus/issues/1810
@@ -118,22 +129,22 @@ var _ = Describe("Observability:", func() {
 					return nil
 				}
 				return fmt.Errorf("Check no metric data in grafana console error: %v", err)
-			}, EventuallyTimeoutMinute*2, EventuallyIntervalSecond*5).Should(Succeed())
+			}, timeout(utils.TimeoutAddonMetricsStopped), EventuallyIntervalSecond*5).Should(Succeed())
 		})
 
 		It("[Integration] Modifying MCO cr to enable observabilityaddon", func() {
+			enabledAt = time.Now()
 			Eventually(func() error {
 				return utils.ModifyMCOAddonSpecMetrics(testOptions, true)
-			}, EventuallyTimeoutMinute*1, EventuallyIntervalSecond*5).Should(Succeed())
+			}, timeout(utils.TimeoutObjectUpdate), EventuallyIntervalSecond*5).Should(Succeed())
 
-			By("Waiting for MCO addon components ready")
-			Eventually(func() bool {
+			eventually("Waiting for MCO addon components ready", func() bool {
 				err, podList := utils.GetPodList(testOptions, false, MCO_ADDON_NAMESPACE, "component=metrics-collector")
 				if len(podList.Items) == 1 && err == nil {
 					return true
 				}
 				return false
-			}, EventuallyTimeoutMinute*6, EventuallyIntervalSecond*5).Should(BeTrue())
+			}, timeout(utils.TimeoutAddonReady), EventuallyIntervalSecond*5).Should(BeTrue())
 
 			By("Checking the status in managedclusteraddon reflects the endpoint operator status correctly")
 			if clusterName != "" {
@@ -147,7 +158,28 @@ var _ = Describe("Observability:", func() {
 						}
 					}
 					return ""
-				}, EventuallyTimeoutMinute*3, EventuallyIntervalSecond*5).Should(Equal("True"))
+				}, timeout(utils.TimeoutAddonStatus), EventuallyIntervalSecond*5).Should(Equal("True"))
+			}
+
+			if clusterName != "" && !disabledAt.IsZero() {
+				By("Checking the only metric gap is the period when observabilityaddon was disabled")
+				maxGap, err := utils.GetMetricGapThreshold(testOptions, 3)
+				Expect(err).ToNot(HaveOccurred())
+				Eventually(func() error {
+					// the gap ends at the first sample ingested after observabilityaddon is enabled again
+					gaps, err := utils.FindManagedClusterMetricGaps(testOptions, "node_memory_MemAvailable_bytes", []string{clusterName},
+						disabledAt.Add(-2*time.Minute), enabledAt, maxGap)
+					if err != nil {
+						return err
+					}
+					if len(gaps) != 1 {
+						return fmt.Errorf("expected 1 gap when observabilityaddon was disabled but got %v", gaps)
+					}
+					if gaps[0].Start.Before(disabledAt.Add(-maxGap)) || !gaps[0].End.After(enabledAt) {
+						return fmt.Errorf("the gap %v does not match the disabled period from %v to %v", gaps[0], disabledAt, enabledAt)
+					}
+					return nil
+				}, timeout(utils.TimeoutMetricVisible), EventuallyIntervalSecond*10).Should(Succeed())
 			}
 		})
 	})
@@ -162,7 +194,7 @@ var _ = Describe("Observability:", func() {
 			}
 			klog.V(1).Infof("error message: <%s>\n", err.Error())
 			return false
-		}, EventuallyTimeoutMinute*1, EventuallyIntervalSecond*1).Should(BeTrue())
+		}, timeout(utils.TimeoutObjectUpdate), EventuallyIntervalSecond*1).Should(BeTrue())
 
 		By("Set interval to 3601")
 		Eventually(func() bool {
@@ -173,40 +205,43 @@ var _ = Describe("Observability:", func() {
 			}
 			klog.V(1).Infof("error message: <%s>\n", err.Error())
 			return false
-		}, EventuallyTimeoutMinute*1, EventuallyIntervalSecond*1).Should(BeTrue())
+		}, timeout(utils.TimeoutObjectUpdate), EventuallyIntervalSecond*1).Should(BeTrue())
 	})
 
 	Context("[P2][Sev2][Observability] Disable the Observability by updating managed cluster label (addon/g0) -", func() {
+		BeforeEach(func() {
+			// the addon components log errors while the observability is disabled and enabled
+			logScanner.AllowBursts()
+		})
+
 		It("[Stable] Modifying managedcluster cr to disable observability", func() {
 			Skip("Modifying managedcluster cr to disable observability")
 			Eventually(func() error {
 				return utils.UpdateObservabilityFromManagedCluster(testOptions, false)
-			}, EventuallyTimeoutMinute*5, EventuallyIntervalSecond*5).Should(Succeed())
+			}, timeout(utils.TimeoutReconcile), EventuallyIntervalSecond*5).Should(Succeed())
 
-			By("Waiting for MCO addon components scales to 0")
-			Eventually(func() bool {
+			eventually("Waiting for MCO addon components scales to 0", func() bool {
 				_, podList := utils.GetPodList(testOptions, false, MCO_ADDON_NAMESPACE, "component=metrics-collector")
 				if len(podList.Items) == 0 && err == nil {
 					return true
 				}
 				return false
-			}, EventuallyTimeoutMinute*5, EventuallyIntervalSecond*5).Should(BeTrue())
+			}, timeout(utils.TimeoutAddonScaleDown), EventuallyIntervalSecond*5).Should(BeTrue())
 		})
 
 		It("[Integration] Modifying managedcluster cr to enable observability", func() {
 			Skip("Modifying managedcluster cr to enable observability")
 			Eventually(func() error {
 				return utils.UpdateObservabilityFromManagedCluster(testOptions, true)
-			}, EventuallyTimeoutMinute*5, EventuallyIntervalSecond*5).Should(Succeed())
+			}, timeout(utils.TimeoutReconcile), EventuallyIntervalSecond*5).Should(Succeed())
 
-			By("Waiting for MCO addon components ready")
-			Eventually(func() bool {
+			eventually("Waiting for MCO addon components ready", func() bool {
 				err, podList := utils.GetPodList(testOptions, false, MCO_ADDON_NAMESPACE, "component=metrics-collector")
 				if len(podList.Items) == 1 && err == nil {
 					return true
 				}
 				return false
-			}, EventuallyTimeoutMinute*5, EventuallyIntervalSecond*5).Should(BeTrue())
+			}, timeout(utils.TimeoutAddonReady), EventuallyIntervalSecond*5).Should(BeTrue())
 		})
 	})
 
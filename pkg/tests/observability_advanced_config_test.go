@@ -28,61 +28,6 @@ var _ = Describe("Observability:", func() {
 			testOptions.HubCluster.KubeContext)
 	})
 
-	componentMap := map[string]struct {
-		// deployment or statefulset
-		Type  string
-		Label string
-	}{
-		"alertmanager": {
-			Type:  "Statefulset",
-			Label: ALERTMANAGER_LABEL,
-		},
-		"grafana": {
-			Type:  "Deployment",
-			Label: GRAFANA_LABEL,
-		},
-		"observatoriumAPI": {
-			Type:  "Deployment",
-			Label: OBSERVATORIUM_API_LABEL,
-		},
-		"rbacQueryProxy": {
-			Type:  "Deployment",
-			Label: RBAC_QUERY_PROXY_LABEL,
-		},
-		"compact": {
-			Type:  "Statefulset",
-			Label: THANOS_COMPACT_LABEL,
-		},
-		"query": {
-			Type:  "Deployment",
-			Label: THANOS_QUERY_LABEL,
-		},
-		"queryFrontend": {
-			Type:  "Deployment",
-			Label: THANOS_QUERY_FRONTEND_LABEL,
-		},
-		"queryFrontendMemcached": {
-			Type:  "Statefulset",
-			Label: THANOS_QUERY_FRONTEND_MEMCACHED_LABEL,
-		},
-		"receive": {
-			Type:  "Statefulset",
-			Label: THANOS_RECEIVE_LABEL,
-		},
-		"rule": {
-			Type:  "Statefulset",
-			Label: THANOS_RULE_LABEL,
-		},
-		"storeMemcached": {
-			Type:  "Statefulset",
-			Label: THANOS_STORE_MEMCACHED_LABEL,
-		},
-		"store": {
-			Type:  "Statefulset",
-			Label: THANOS_STORE_LABEL,
-		},
-	}
-
 	It("[P1][Sev1][Observability][Integration] Checking replicas in advanced config for each component (config/g0)", func() {
 
 		mcoRes, err := dynClient.Resource(utils.NewMCOGVRV1BETA2()).Get(MCO_CR_NAME, metav1.GetOptions{})
@@ -97,24 +42,16 @@ var _ = Describe("Observability:", func() {
 
 		advancedSpec := mcoRes.Object["spec"].(map[string]interface{})["advanced"].(map[string]interface{})
 
-		for key, component := range componentMap {
-			if key == "compact" || key == "store" {
+		for _, component := range utils.HubComponents() {
+			if component.AdvancedKey == "" || !component.ReplicasConfigurable {
 				continue
 			}
-			klog.V(1).Infof("The component is: %s\n", key)
-			replicas := advancedSpec[key].(map[string]interface{})["replicas"]
-			if component.Type == "Deployment" {
-				deploys, err := utils.GetDeploymentWithLabel(testOptions, true, component.Label, MCO_NAMESPACE)
-				Expect(err).NotTo(HaveOccurred())
-				for _, deployInfo := range (*deploys).Items {
-					Expect(int(replicas.(int64))).To(Equal(int(*deployInfo.Spec.Replicas)))
-				}
-			} else {
-				sts, err := utils.GetStatefulSetWithLabel(testOptions, true, component.Label, MCO_NAMESPACE)
-				Expect(err).NotTo(HaveOccurred())
-				for _, stsInfo := range (*sts).Items {
-					Expect(int(replicas.(int64))).To(Equal(int(*stsInfo.Spec.Replicas)))
-				}
+			klog.V(1).Infof("The component is: %s\n", component.Name)
+			replicas := advancedSpec[component.AdvancedKey].(map[string]interface{})["replicas"]
+			workloads, err := component.GetWorkloads(testOptions)
+			Expect(err).NotTo(HaveOccurred())
+			for _, workload := range workloads {
+				Expect(int(replicas.(int64))).To(Equal(int(workload.Replicas)))
 			}
 		}
 	})
@@ -132,9 +69,12 @@ var _ = Describe("Observability:", func() {
 
 		advancedSpec := mcoRes.Object["spec"].(map[string]interface{})["advanced"].(map[string]interface{})
 
-		for key, component := range componentMap {
-			klog.V(1).Infof("The component is: %s\n", key)
-			resources := advancedSpec[key].(map[string]interface{})["resources"]
+		for _, component := range utils.HubComponents() {
+			if component.AdvancedKey == "" {
+				continue
+			}
+			klog.V(1).Infof("The component is: %s\n", component.Name)
+			resources := advancedSpec[component.AdvancedKey].(map[string]interface{})["resources"]
 			limits := resources.(map[string]interface{})["limits"].(map[string]interface{})
 			var cpu string
 			switch v := limits["cpu"].(type) {
@@ -143,21 +83,11 @@ var _ = Describe("Observability:", func() {
 			default:
 				cpu = limits["cpu"].(string)
 			}
-			if component.Type == "Deployment" {
-				deploys, err := utils.GetDeploymentWithLabel(testOptions, true, component.Label, MCO_NAMESPACE)
-				Expect(err).NotTo(HaveOccurred())
-				for _, deployInfo := range (*deploys).Items {
-					Expect(cpu).To(Equal(deployInfo.Spec.Template.Spec.Containers[0].Resources.Limits.Cpu().String()))
-					Expect(limits["memory"]).To(Equal(deployInfo.Spec.Template.Spec.Containers[0].Resources.Limits.Memory().String()))
-				}
-			} else {
-				sts, err := utils.GetStatefulSetWithLabel(testOptions, true, component.Label, MCO_NAMESPACE)
-				Expect(err).NotTo(HaveOccurred())
-				for _, stsInfo := range (*sts).Items {
-					Expect(cpu).To(Equal(stsInfo.Spec.Template.Spec.Containers[0].Resources.Limits.Cpu().String()))
-					Expect(limits["memory"]).To(Equal(stsInfo.Spec.Template.Spec.Containers[0].Resources.Limits.Memory().String()))
-				}
-
+			workloads, err := component.GetWorkloads(testOptions)
+			Expect(err).NotTo(HaveOccurred())
+			for _, workload := range workloads {
+				Expect(cpu).To(Equal(workload.Template.Spec.Containers[0].Resources.Limits.Cpu().String()))
+				Expect(limits["memory"]).To(Equal(workload.Template.Spec.Containers[0].Resources.Limits.Memory().String()))
 			}
 		}
 	})
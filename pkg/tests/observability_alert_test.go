@@ -131,39 +131,18 @@ var _ = Describe("Observability:", func() {
 
 	It("[P2][Sev2][Observability][Stable] Verify alert is created and received - Should have custom alert generated (alert/g0)", func() {
 		By("Creating custom alert rules")
-
-		rules, err := hubClient.AppsV1().StatefulSets(MCO_NAMESPACE).List(metav1.ListOptions{
-			LabelSelector: THANOS_RULE_LABEL,
-		})
+		// the rule pods restart to load the custom rules
+		tracker, err := utils.NewComponentRolloutTracker(testOptions, "thanos-rule")
 		Expect(err).NotTo(HaveOccurred())
-		Expect(len(rules.Items)).NotTo(Equal(0))
-
-		stsName := (*rules).Items[0].Name
-		oldSts, _ := utils.GetStatefulSet(testOptions, true, stsName, MCO_NAMESPACE)
 
 		yamlB, err := kustomize.Render(kustomize.Options{KustomizationPath: "../../observability-gitops/alerts/custom_rules_valid"})
 		Expect(err).NotTo(HaveOccurred())
 		Expect(utils.Apply(testOptions.HubCluster.MasterURL, testOptions.KubeConfig, testOptions.HubCluster.KubeContext, yamlB)).NotTo(HaveOccurred())
 
-		ThanosRuleRestarting := false
-		By("Wait for thanos rule pods are restarted and ready")
 		// ensure the thanos rule pods are restarted successfully before processing
-		Eventually(func() error {
-			if !ThanosRuleRestarting {
-				newSts, _ := utils.GetStatefulSet(testOptions, true, stsName, MCO_NAMESPACE)
-				if oldSts.GetResourceVersion() == newSts.GetResourceVersion() {
-					return fmt.Errorf("The %s is not being restarted in 10 minutes", stsName)
-				} else {
-					ThanosRuleRestarting = true
-				}
-			}
-
-			err = utils.CheckStatefulSetPodReady(testOptions, stsName)
-			if err != nil {
-				return err
-			}
-			return nil
-		}, EventuallyTimeoutMinute*10, EventuallyIntervalSecond*5).Should(Succeed())
+		await("Wait for thanos rule pods are restarted and ready", func() error {
+			return tracker.Wait(timeout(utils.TimeoutRolloutRestart))
+		})
 
 		var labelName, labelValue string
 		labels, err := kustomize.GetLabels(yamlB)
@@ -177,7 +156,7 @@ var _ = Describe("Observability:", func() {
 			err, _ := utils.ContainManagedClusterMetric(testOptions, `ALERTS{`+labelName+`="`+labelValue+`"}`,
 				[]string{`"__name__":"ALERTS"`, `"` + labelName + `":"` + labelValue + `"`})
 			return err
-		}, EventuallyTimeoutMinute*5, EventuallyIntervalSecond*5).Should(Succeed())
+		}, timeout(utils.TimeoutAlertFired), EventuallyIntervalSecond*5).Should(Succeed())
 	})
 
 	It("[P2][Sev2][Observability][Stable] Should modify the SECRET: alertmanager-config (alert/g0)", func() {
@@ -205,46 +184,22 @@ var _ = Describe("Observability:", func() {
 			err, _ := utils.ContainManagedClusterMetric(testOptions, `ALERTS{`+labelName+`="`+labelValue+`"}`,
 				[]string{`"__name__":"ALERTS"`, `"` + labelName + `":"` + labelValue + `"`})
 			return err
-		}, EventuallyTimeoutMinute*5, EventuallyIntervalSecond*5).Should(MatchError("Failed to find metric name from response"))
+		}, timeout(utils.TimeoutAlertFired), EventuallyIntervalSecond*5).Should(MatchError("Failed to find metric name from response"))
 	})
 
 	It("[P2][Sev2][Observability][Stable] Updated alert rule can take effect automatically - delete the customized rules (alert/g0)", func() {
-
-		rules, err := hubClient.AppsV1().StatefulSets(MCO_NAMESPACE).List(metav1.ListOptions{
-			LabelSelector: THANOS_RULE_LABEL,
-		})
+		tracker, err := utils.NewComponentRolloutTracker(testOptions, "thanos-rule")
 		Expect(err).NotTo(HaveOccurred())
-		Expect(len(rules.Items)).NotTo(Equal(0))
-
-		stsName := (*rules).Items[0].Name
-
-		oldSts, _ := utils.GetStatefulSet(testOptions, true, stsName, MCO_NAMESPACE)
 
 		Eventually(func() error {
 			err := hubClient.CoreV1().ConfigMaps(MCO_NAMESPACE).Delete(configmap[1], &metav1.DeleteOptions{})
 			return err
-		}, EventuallyTimeoutMinute*1, EventuallyIntervalSecond*1).Should(Succeed())
+		}, timeout(utils.TimeoutObjectUpdate), EventuallyIntervalSecond*1).Should(Succeed())
 
-		ThanosRuleRestarting := false
-		By("Wait for thanos rule pods are restarted and ready")
 		// ensure the thanos rule pods are restarted successfully before processing
-		Eventually(func() error {
-			if !ThanosRuleRestarting {
-				newSts, _ := utils.GetStatefulSet(testOptions, true, stsName, MCO_NAMESPACE)
-
-				if oldSts.GetResourceVersion() == newSts.GetResourceVersion() {
-					return fmt.Errorf("The %s is not being restarted in 10 minutes", stsName)
-				} else {
-					ThanosRuleRestarting = true
-				}
-			}
-
-			err = utils.CheckStatefulSetPodReady(testOptions, stsName)
-			if err != nil {
-				return err
-			}
-			return nil
-		}, EventuallyTimeoutMinute*10, EventuallyIntervalSecond*5).Should(Succeed())
+		await("Wait for thanos rule pods are restarted and ready", func() error {
+			return tracker.Wait(timeout(utils.TimeoutRolloutRestart))
+		})
 
 		klog.V(3).Infof("Successfully deleted CM: thanos-ruler-custom-rules")
 	})
@@ -328,7 +283,7 @@ var _ = Describe("Observability:", func() {
 			}
 
 			return nil
-		}, EventuallyTimeoutMinute*5, EventuallyIntervalSecond*5).Should(Succeed())
+		}, timeout(utils.TimeoutAlertFired), EventuallyIntervalSecond*5).Should(Succeed())
 	})
 
 	JustAfterEach(func() {
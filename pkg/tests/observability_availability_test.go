@@ -0,0 +1,122 @@
+// Copyright (c) 2021 Red Hat, Inc.
+// Copyright Contributors to the Open Cluster Management project
+
+package tests
+
+import (
+	"fmt"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"k8s.io/klog"
+
+	"github.com/stolostron/observability-e2e-test/pkg/utils"
+)
+
+var _ = Describe("Observability:", func() {
+	var (
+		clusterName  string
+		availability string
+	)
+
+	BeforeEach(func() {
+		hubClient = utils.NewKubeClient(
+			testOptions.HubCluster.MasterURL,
+			testOptions.KubeConfig,
+			testOptions.HubCluster.KubeContext)
+
+		dynClient = utils.NewKubeClientDynamic(
+			testOptions.HubCluster.MasterURL,
+			testOptions.KubeConfig,
+			testOptions.HubCluster.KubeContext)
+
+		clusterName = utils.GetManagedClusterName(testOptions)
+		if clusterName == "" {
+			clusterName = utils.LocalClusterName
+		}
+		var err error
+		availability, err = utils.GetMCOAvailabilityConfig(testOptions)
+		Expect(err).NotTo(HaveOccurred())
+		// the components log errors while the switch scales and restarts them
+		logScanner.AllowBursts()
+	})
+
+	It("[P2][Sev2][Observability][Integration] Should switch the availability config between Basic and High without data loss (availability/g0)", func() {
+		By("Checking the components match the current availability config " + availability)
+		Eventually(func() error {
+			return utils.CheckAvailabilityConfig(testOptions, availability)
+		}, timeout(utils.TimeoutResourceReady), EventuallyIntervalSecond*10).Should(Succeed())
+
+		// the samples collected before the transition must stay queryable after the transition
+		query := fmt.Sprintf(`count_over_time(node_memory_MemAvailable_bytes{cluster="%s"}[10m])`, clusterName)
+		ts := time.Now().Add(-time.Minute)
+		countSamples := func() (float64, error) {
+			result, err := utils.QueryMetricAt(testOptions, query, ts)
+			if err != nil {
+				return 0, err
+			}
+			if len(result.Data.Result) == 0 {
+				return 0, fmt.Errorf("no data at %v for %s", ts, query)
+			}
+			sample, err := result.Data.Result[0].Sample()
+			if err != nil {
+				return 0, err
+			}
+			return sample.Value, nil
+		}
+		before, err := countSamples()
+		Expect(err).NotTo(HaveOccurred())
+		klog.V(1).Infof("Found %v samples of cluster %s before the transition", before, clusterName)
+		start := time.Now()
+
+		modes := []string{utils.AvailabilityBasic, utils.AvailabilityHigh}
+		if availability == utils.AvailabilityBasic {
+			modes = []string{utils.AvailabilityHigh, utils.AvailabilityBasic}
+		}
+		for _, mode := range modes {
+			By("Switching the availability config to " + mode)
+			Expect(utils.ModifyMCOAvailabilityConfig(testOptions, mode)).NotTo(HaveOccurred())
+
+			eventually("Waiting for the components to match the availability config "+mode, func() error {
+				return utils.CheckAvailabilityConfig(testOptions, mode)
+			}, timeout(utils.TimeoutAvailabilitySwitch), EventuallyIntervalSecond*10).Should(Succeed())
+
+			// the pods retry the mount while the volumes are attached, only the failures that persist count
+			By("Checking no pod failed to be created or mounted after switching to " + mode)
+			Expect(eventRecorder.CheckNoPersistentWarnings(2*time.Minute, "FailedCreate", "FailedMount", "FailedAttachVolume")).To(Succeed())
+
+			By("Checking no data is lost after switching to " + mode)
+			Eventually(func() error {
+				after, err := countSamples()
+				if err != nil {
+					return err
+				}
+				if after < before {
+					return fmt.Errorf("found %v samples at %v after switching to %s but %v before", after, ts, mode, before)
+				}
+				return nil
+			}, timeout(utils.TimeoutMetricVisible), EventuallyIntervalSecond*10).Should(Succeed())
+			checkedAt := time.Now()
+			Eventually(func() error {
+				return utils.CheckManagedClusterMetricContinuity(testOptions, "node_memory_MemAvailable_bytes", []string{clusterName},
+					start, checkedAt, 3)
+			}, timeout(utils.TimeoutMetricVisible), EventuallyIntervalSecond*10).Should(Succeed())
+		}
+	})
+
+	JustAfterEach(func() {
+		Expect(utils.IntegrityChecking(testOptions)).NotTo(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		if CurrentGinkgoTestDescription().Failed {
+			utils.PrintMCOObject(testOptions)
+			utils.PrintAllMCOPodsStatus(testOptions)
+			utils.PrintAllOBAPodsStatus(testOptions)
+			// restore the availability config for the following cases
+			Expect(utils.ModifyMCOAvailabilityConfig(testOptions, availability)).NotTo(HaveOccurred())
+		}
+		testFailed = testFailed || CurrentGinkgoTestDescription().Failed
+	})
+})
@@ -0,0 +1,132 @@
+// Copyright (c) 2021 Red Hat, Inc.
+// Copyright Contributors to the Open Cluster Management project
+
+package tests
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"k8s.io/klog"
+
+	"github.com/stolostron/observability-e2e-test/pkg/utils"
+)
+
+const (
+	// the duration for thanos-receive to cut and upload the first block, it is restarted to upload the
+	// block when it is running for less
+	receiveUploadDuration = 2*time.Hour + 30*time.Minute
+)
+
+var _ = Describe("Observability:", func() {
+	var (
+		bucketClient *utils.S3Client
+		stopBucket   func()
+	)
+
+	BeforeEach(func() {
+		hubClient = utils.NewKubeClient(
+			testOptions.HubCluster.MasterURL,
+			testOptions.KubeConfig,
+			testOptions.HubCluster.KubeContext)
+
+		dynClient = utils.NewKubeClientDynamic(
+			testOptions.HubCluster.MasterURL,
+			testOptions.KubeConfig,
+			testOptions.HubCluster.KubeContext)
+
+		if os.Getenv("OBJECT_STORAGE_ENDPOINT") == "" {
+			_, podList := utils.GetPodList(testOptions, true, MCO_NAMESPACE, utils.MinIOLabel)
+			if podList == nil || len(podList.Items) == 0 {
+				Skip("Skip the case since the in-cluster minio is not deployed")
+			}
+		}
+		var err error
+		bucketClient, stopBucket, err = utils.NewMinIOBucketClient(testOptions)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("[P2][Sev2][Observability][Integration] Should have blocks uploaded by thanos-receive (bucket/g0)", func() {
+		stsList, err := utils.GetStatefulSetWithLabel(testOptions, true, THANOS_RECEIVE_LABEL, MCO_NAMESPACE)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(stsList.Items).NotTo(BeEmpty())
+		if age := time.Since(stsList.Items[0].CreationTimestamp.Time); age < receiveUploadDuration {
+			// thanos-receive flushes the head block and uploads it when it is shut down, one replica is
+			// restarted so that the others keep receiving the metrics
+			err, podList := utils.GetPodList(testOptions, true, MCO_NAMESPACE, THANOS_RECEIVE_LABEL)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(podList.Items).NotTo(BeEmpty())
+			pod := podList.Items[0]
+			logScanner.AllowBursts()
+			By(fmt.Sprintf("Restarting %s running for %v to upload its head block", pod.Name, age.Round(time.Minute)))
+			Expect(utils.DeletePod(testOptions, true, MCO_NAMESPACE, pod.Name)).NotTo(HaveOccurred())
+			eventually(fmt.Sprintf("Waiting for %s to be restarted", pod.Name), func() error {
+				err, podList := utils.GetPodList(testOptions, true, MCO_NAMESPACE, THANOS_RECEIVE_LABEL)
+				if err != nil {
+					return err
+				}
+				for _, p := range podList.Items {
+					if p.Name != pod.Name {
+						continue
+					}
+					if p.UID == pod.UID {
+						return fmt.Errorf("pod %s is not deleted yet", pod.Name)
+					}
+					if !utils.IsPodReady(p) {
+						return fmt.Errorf("pod %s is not ready yet", pod.Name)
+					}
+					return nil
+				}
+				return fmt.Errorf("pod %s is not recreated yet", pod.Name)
+			}, timeout(utils.TimeoutRolloutRestart), EventuallyIntervalSecond*5).Should(Succeed())
+		}
+
+		By("Checking the blocks uploaded by thanos-receive")
+		Eventually(func() error {
+			blocks, err := utils.ListBlocks(bucketClient)
+			if err != nil {
+				return err
+			}
+			for _, block := range blocks {
+				if block.Thanos.Source == "receive" {
+					klog.V(1).Infof("Found block uploaded by thanos-receive: %s", block)
+					return nil
+				}
+			}
+			return fmt.Errorf("no block uploaded by thanos-receive in %d blocks", len(blocks))
+		}, timeout(utils.TimeoutBlocksUploaded), EventuallyIntervalSecond*30).Should(Succeed())
+	})
+
+	It("[P2][Sev2][Observability][Integration] Should have blocks compacted, downsampled and retained by thanos-compact (bucket/g0)", func() {
+		retention, err := utils.GetRetentionConfig(testOptions)
+		Expect(err).NotTo(HaveOccurred())
+
+		By(fmt.Sprintf("Checking the blocks against the compaction, downsampling and retention %+v", retention))
+		blocks, err := utils.ListBlocks(bucketClient)
+		Expect(err).NotTo(HaveOccurred())
+		for _, block := range blocks {
+			klog.V(1).Infof("Block %s", block)
+		}
+		Expect(utils.VerifyBlocks(blocks, time.Now(), retention)).To(BeEmpty())
+	})
+
+	JustAfterEach(func() {
+		Expect(utils.IntegrityChecking(testOptions)).NotTo(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		if stopBucket != nil {
+			stopBucket()
+			stopBucket = nil
+		}
+		if CurrentGinkgoTestDescription().Failed {
+			utils.PrintMCOObject(testOptions)
+			utils.PrintAllMCOPodsStatus(testOptions)
+			utils.PrintAllOBAPodsStatus(testOptions)
+		}
+		testFailed = testFailed || CurrentGinkgoTestDescription().Failed
+	})
+})
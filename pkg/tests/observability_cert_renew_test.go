@@ -9,9 +9,9 @@ import (
 
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
-	"k8s.io/klog"
 
 	"github.com/stolostron/observability-e2e-test/pkg/utils"
+	"github.com/stolostron/observability-e2e-test/pkg/utils/certs"
 )
 
 var _ = Describe("Observability:", func() {
@@ -29,118 +29,100 @@ var _ = Describe("Observability:", func() {
 
 	It("[P1][Sev1][Observability][Integration] Verify Observability Certificate rotation - Should have metrics collector pod restart if cert secret re-generated (certrenew/g0)", func() {
 		By("Waiting for pods ready: observability-observatorium-api, observability-rbac-query-proxy, metrics-collector-deployment")
-		// sleep 30s to wait for installation is ready
-		time.Sleep(30 * time.Second)
-		collectorPodName := ""
-		hubPodsName := []string{}
-		Eventually(func() bool {
-			if collectorPodName == "" {
-				_, podList := utils.GetPodList(testOptions, false, MCO_ADDON_NAMESPACE, "component=metrics-collector")
-				if podList != nil && len(podList.Items) > 0 {
-					collectorPodName = podList.Items[0].Name
+		components := []string{"observatorium-api", "rbac-query-proxy", "metrics-collector"}
+		Eventually(func() error {
+			for _, name := range components {
+				c, err := utils.GetComponent(name)
+				if err != nil {
+					return err
 				}
-			}
-			if collectorPodName == "" {
-				return false
-			}
-			hubPodsName = []string{}
-			_, apiPodList := utils.GetPodList(testOptions, true, MCO_NAMESPACE, "app.kubernetes.io/name=observatorium-api")
-			if apiPodList != nil && len(apiPodList.Items) != 0 {
-				for _, pod := range apiPodList.Items {
-					hubPodsName = append(hubPodsName, pod.Name)
+				if err := c.CheckRolledOut(testOptions); err != nil {
+					return err
 				}
-			} else {
-				return false
-			}
-			_, rbacPodList := utils.GetPodList(testOptions, true, MCO_NAMESPACE, "app=rbac-query-proxy")
-			if rbacPodList != nil && len(rbacPodList.Items) != 0 {
-				for _, pod := range rbacPodList.Items {
-					hubPodsName = append(hubPodsName, pod.Name)
-				}
-			} else {
-				return false
 			}
+			return nil
+		}, timeout(utils.TimeoutRolloutRestart), EventuallyIntervalSecond*5).Should(Succeed())
 
-			return true
-		}, EventuallyTimeoutMinute*5, EventuallyIntervalSecond*5).Should(BeTrue())
+		By("Checking the certificates before renew")
+		oldHubCerts, err := certs.LoadHubCerts(testOptions)
+		Expect(err).ToNot(HaveOccurred())
+		oldHubCerts.Log()
+		Expect(oldHubCerts.Verify()).To(Succeed())
+		oldClientCert, err := certs.LoadKeyPair(testOptions, false, utils.ManagedClusterClientCerts, MCO_ADDON_NAMESPACE)
+		Expect(err).ToNot(HaveOccurred())
 
 		By("Deleting certificate secret to simulate certificate renew")
-		err := utils.DeleteCertSecret(testOptions)
+		// the components reload the renewed certificates
+		Expect(restartMonitor.ExpectComponentRestarts("observatorium-api", "rbac-query-proxy", "metrics-collector")).To(Succeed())
+		trackers := []*utils.RolloutTracker{}
+		for _, name := range components {
+			tracker, err := utils.NewComponentRolloutTracker(testOptions, name)
+			Expect(err).ToNot(HaveOccurred())
+			trackers = append(trackers, tracker)
+		}
+		renewedAt := time.Now()
+		err = utils.DeleteCertSecret(testOptions)
 		Expect(err).ToNot(HaveOccurred())
 
-		By(fmt.Sprintf("Waiting for old pods removed: %v and new pods created", hubPodsName))
-		Eventually(func() bool {
-			err1, appPodList := utils.GetPodList(testOptions, true, MCO_NAMESPACE, "app.kubernetes.io/name=observatorium-api")
-			err2, rbacPodList := utils.GetPodList(testOptions, true, MCO_NAMESPACE, "app=rbac-query-proxy")
-			if err1 == nil && err2 == nil {
-				if len(hubPodsName) != len(appPodList.Items)+len(rbacPodList.Items) {
-					klog.V(1).Infof("Wrong number of pods: <%d> observatorium-api pods and <%d> rbac-query-proxy pods", len(appPodList.Items), len(rbacPodList.Items))
-					return false
-				}
-				for _, oldPodName := range hubPodsName {
-					for _, pod := range appPodList.Items {
-						if oldPodName == pod.Name {
-							klog.V(1).Infof("<%s> not removed yet", oldPodName)
-							return false
-						}
-						if pod.Status.Phase != "Running" {
-							klog.V(1).Infof("<%s> not in Running status yet", pod.Name)
-							return false
-						}
-					}
-					for _, pod := range rbacPodList.Items {
-						if oldPodName == pod.Name {
-							klog.V(1).Infof("<%s> not removed yet", oldPodName)
-							return false
-						}
-						if pod.Status.Phase != "Running" {
-							klog.V(1).Infof("<%s> not in Running status yet", pod.Name)
-							return false
-						}
-					}
-				}
-				return true
-			}
+		for i, tracker := range trackers {
+			tracker := tracker
+			await(fmt.Sprintf("Waiting for %s to restart", components[i]), func() error {
+				return tracker.Wait(timeout(utils.TimeoutRolloutRestart))
+			})
+		}
 
-			// debug code to check label "cert/time-restarted"
-			deploys, err := utils.GetDeploymentWithLabel(testOptions, true, OBSERVATORIUM_API_LABEL, MCO_NAMESPACE)
-			if err == nil {
-				for _, deployInfo := range (*deploys).Items {
-					klog.V(1).Infof("labels: <%v>", deployInfo.Spec.Template.ObjectMeta.Labels)
-				}
+		By("Checking new certificates are issued and chain to the new CAs")
+		Eventually(func() error {
+			hubCerts, err := certs.LoadHubCerts(testOptions)
+			if err != nil {
+				return err
 			}
-
-			deploys, err = utils.GetDeploymentWithLabel(testOptions, true, RBAC_QUERY_PROXY_LABEL, MCO_NAMESPACE)
-			if err == nil {
-				for _, deployInfo := range (*deploys).Items {
-					klog.V(1).Infof("labels: <%v>", deployInfo.Spec.Template.ObjectMeta.Labels)
-				}
+			if hubCerts.ServerCA.Equal(oldHubCerts.ServerCA) || hubCerts.ClientCA.Equal(oldHubCerts.ClientCA) ||
+				hubCerts.Server.Equal(oldHubCerts.Server) || hubCerts.Grafana.Equal(oldHubCerts.Grafana) {
+				return fmt.Errorf("certificates are not renewed yet")
 			}
-			return false
-		}, EventuallyTimeoutMinute*5, EventuallyIntervalSecond*5).Should(BeTrue())
-
-		By(fmt.Sprintf("Waiting for old pod <%s> removed and new pod created", collectorPodName))
-		Eventually(func() bool {
-			err, podList := utils.GetPodList(testOptions, false, MCO_ADDON_NAMESPACE, "component=metrics-collector")
-			if err == nil {
-				for _, pod := range podList.Items {
-					if pod.Name != collectorPodName {
-						if pod.Status.Phase != "Running" {
-							klog.V(1).Infof("<%s> not in Running status yet", pod.Name)
-							return false
-						}
-						return true
-					}
-				}
-
+			if err := hubCerts.Verify(); err != nil {
+				return err
+			}
+			managedCerts, err := certs.LoadManagedClusterCerts(testOptions)
+			if err != nil {
+				return err
 			}
-			// debug code to check label "cert/time-restarted"
-			deployment, err := utils.GetDeployment(testOptions, false, "metrics-collector-deployment", MCO_ADDON_NAMESPACE)
-			if err == nil {
-				klog.V(1).Infof("labels: <%v>", deployment.Spec.Template.ObjectMeta.Labels)
+			if err := hubCerts.VerifyManagedCluster(managedCerts); err != nil {
+				return err
 			}
-			return false
-		}, EventuallyTimeoutMinute*5, EventuallyIntervalSecond*5).Should(BeTrue())
+			hubCerts.Log()
+			managedCerts.Log()
+			return certs.CheckMetricsCollectorCert(testOptions, managedCerts.Client)
+		}, timeout(utils.TimeoutReconcile), EventuallyIntervalSecond*10).Should(Succeed())
+
+		By("Checking observatorium-api accepts the new client certificate and rejects the old one")
+		Expect(logScanner.Allow("TLS handshake error")).To(Succeed())
+		endpoint := utils.GetObservatoriumAPIWriteURL(testOptions)
+		roots, err := certs.GetObservatoriumAPIRootCAs(testOptions)
+		Expect(err).ToNot(HaveOccurred())
+		newClientCert, err := certs.LoadKeyPair(testOptions, false, utils.ManagedClusterClientCerts, MCO_ADDON_NAMESPACE)
+		Expect(err).ToNot(HaveOccurred())
+		Eventually(func() bool {
+			return certs.ProbeObservatoriumAPI(endpoint, roots, newClientCert).Accepted()
+		}, timeout(utils.TimeoutReconcile), EventuallyIntervalSecond*10).Should(BeTrue())
+		result := certs.ProbeObservatoriumAPI(endpoint, roots, oldClientCert)
+		// the old client certificate is signed by the old client CA
+		Expect(result.RejectedAs(certs.RejectionUnknownCA)).To(BeTrue(), "expected the old client certificate to be rejected as %v, got %s",
+			certs.RejectionUnknownCA, result)
+
+		clusterName := utils.GetManagedClusterName(testOptions)
+		if clusterName == "" {
+			clusterName = utils.LocalClusterName
+		}
+		// the old client certificate is rejected, so the samples received after the check prove
+		// metrics-collector presents the new one
+		By("Checking metric to ensure that no data is lost during certificate renew")
+		checkedAt := time.Now()
+		Eventually(func() error {
+			return utils.CheckManagedClusterMetricContinuity(testOptions, "node_memory_MemAvailable_bytes", []string{clusterName},
+				renewedAt.Add(-2*time.Minute), checkedAt, 3)
+		}, timeout(utils.TimeoutMetricVisible), EventuallyIntervalSecond*10).Should(Succeed())
 	})
 
 	JustAfterEach(func() {
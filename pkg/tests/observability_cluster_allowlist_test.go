@@ -0,0 +1,117 @@
+// Copyright (c) 2021 Red Hat, Inc.
+// Copyright Contributors to the Open Cluster Management project
+
+package tests
+
+import (
+	"fmt"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/stolostron/observability-e2e-test/pkg/utils"
+)
+
+const (
+	clusterAllowlistMetric = "node_memory_Buffers_bytes"
+)
+
+var _ = Describe("Observability:", func() {
+	var (
+		targetCluster string
+		otherClusters []string
+	)
+
+	BeforeEach(func() {
+		hubClient = utils.NewKubeClient(
+			testOptions.HubCluster.MasterURL,
+			testOptions.KubeConfig,
+			testOptions.HubCluster.KubeContext)
+
+		dynClient = utils.NewKubeClientDynamic(
+			testOptions.HubCluster.MasterURL,
+			testOptions.KubeConfig,
+			testOptions.HubCluster.KubeContext)
+
+		targetCluster = utils.GetManagedClusterName(testOptions)
+		if targetCluster == "" {
+			targetCluster = utils.LocalClusterName
+		}
+		clusters, err := utils.ListManagedClusterNames(testOptions)
+		Expect(err).NotTo(HaveOccurred())
+		otherClusters = []string{}
+		for _, cluster := range clusters {
+			if cluster != targetCluster {
+				otherClusters = append(otherClusters, cluster)
+			}
+		}
+		// the metrics collector logs errors while the custom allowlist is created and deleted
+		logScanner.AllowBursts()
+	})
+
+	It("[P2][Sev2][Observability][Integration] Customized metrics data are collected from the managed cluster with the custom allowlist (metricslist/g1)", func() {
+		By("Adding custom metrics allowlist configmap to managed cluster " + targetCluster)
+		allowlist := &utils.MetricsAllowlist{Names: []string{clusterAllowlistMetric}}
+		Expect(utils.CreateManagedClusterCustomAllowlist(testOptions, targetCluster, allowlist)).NotTo(HaveOccurred())
+
+		eventually("Waiting for new added metrics from managed cluster "+targetCluster, func() error {
+			found, err := utils.ManagedClusterHasMetric(testOptions, targetCluster, clusterAllowlistMetric)
+			if err != nil {
+				return err
+			}
+			if !found {
+				return fmt.Errorf("metric %s of cluster %s is not found", clusterAllowlistMetric, targetCluster)
+			}
+			return nil
+		}, timeout(utils.TimeoutMetricVisible), EventuallyIntervalSecond*5).Should(Succeed())
+	})
+
+	It("[P2][Sev2][Observability][Integration] Customized metrics data are not collected from other managed clusters (metricslist/g1)", func() {
+		if len(otherClusters) == 0 {
+			Skip("Skip the case since there is no other managed cluster with observability enabled")
+		}
+
+		By("Checking new added metrics are not found for other managed clusters")
+		Consistently(func() error {
+			for _, cluster := range otherClusters {
+				found, err := utils.ManagedClusterHasMetric(testOptions, cluster, clusterAllowlistMetric)
+				if err != nil {
+					return err
+				}
+				if found {
+					return fmt.Errorf("metric %s of cluster %s should not be collected", clusterAllowlistMetric, cluster)
+				}
+			}
+			return nil
+		}, timeout(utils.TimeoutMetricAbsent), EventuallyIntervalSecond*30).Should(Succeed())
+	})
+
+	It("[P2][Sev2][Observability][Integration] Customized metrics data are removed with the custom allowlist of the managed cluster (metricslist/g1)", func() {
+		By("Deleting custom metrics allowlist configmap from managed cluster " + targetCluster)
+		Expect(utils.DeleteManagedClusterCustomAllowlist(testOptions, targetCluster)).NotTo(HaveOccurred())
+
+		eventually("Waiting for new added metrics disappear for managed cluster "+targetCluster, func() error {
+			found, err := utils.ManagedClusterHasMetric(testOptions, targetCluster, clusterAllowlistMetric)
+			if err != nil {
+				return err
+			}
+			if found {
+				return fmt.Errorf("metric %s of cluster %s is still collected", clusterAllowlistMetric, targetCluster)
+			}
+			return nil
+		}, timeout(utils.TimeoutMetricGone), EventuallyIntervalSecond*5).Should(Succeed())
+	})
+
+	JustAfterEach(func() {
+		Expect(utils.IntegrityChecking(testOptions)).NotTo(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		if CurrentGinkgoTestDescription().Failed {
+			utils.PrintMCOObject(testOptions)
+			utils.PrintAllMCOPodsStatus(testOptions)
+			utils.PrintAllOBAPodsStatus(testOptions)
+		}
+		testFailed = testFailed || CurrentGinkgoTestDescription().Failed
+	})
+})
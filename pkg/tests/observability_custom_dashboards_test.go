@@ -37,7 +37,7 @@ var _ = Describe("Observability:", func() {
 		Eventually(func() bool {
 			_, result := utils.ContainDashboard(testOptions, dashboardTitle)
 			return result
-		}, EventuallyTimeoutMinute*3, EventuallyIntervalSecond*5).Should(BeTrue())
+		}, timeout(utils.TimeoutReconcile), EventuallyIntervalSecond*5).Should(BeTrue())
 	})
 
 	It("[P2][Sev2][Observability][Stable] Verify new customized Grafana dashboard - Should have update custom dashboard after configmap updated (dashboard/g0)", func() {
@@ -47,11 +47,11 @@ var _ = Describe("Observability:", func() {
 		Eventually(func() bool {
 			_, result := utils.ContainDashboard(testOptions, dashboardTitle)
 			return result
-		}, EventuallyTimeoutMinute*3, EventuallyIntervalSecond*5).Should(BeFalse())
+		}, timeout(utils.TimeoutReconcile), EventuallyIntervalSecond*5).Should(BeFalse())
 		Eventually(func() bool {
 			_, result := utils.ContainDashboard(testOptions, updateDashboardTitle)
 			return result
-		}, EventuallyTimeoutMinute*3, EventuallyIntervalSecond*5).Should(BeTrue())
+		}, timeout(utils.TimeoutReconcile), EventuallyIntervalSecond*5).Should(BeTrue())
 	})
 
 	It("[P2][Sev2][Observability][Stable] Verify new customized Grafana dashboard - Should have no custom dashboard in grafana after related configmap removed (dashboard/g0)", func() {
@@ -61,7 +61,7 @@ var _ = Describe("Observability:", func() {
 		Eventually(func() bool {
 			_, result := utils.ContainDashboard(testOptions, updateDashboardTitle)
 			return result
-		}, EventuallyTimeoutMinute*3, EventuallyIntervalSecond*5).Should(BeFalse())
+		}, timeout(utils.TimeoutReconcile), EventuallyIntervalSecond*5).Should(BeFalse())
 	})
 
 	JustAfterEach(func() {
@@ -83,7 +83,7 @@ var _ = Describe("Observability:", func() {
 				}
 			}
 			return nil
-		}, EventuallyTimeoutMinute*3, EventuallyIntervalSecond*5).Should(Succeed())
+		}, timeout(utils.TimeoutReconcile), EventuallyIntervalSecond*5).Should(Succeed())
 	})
 
 	JustAfterEach(func() {
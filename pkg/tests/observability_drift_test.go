@@ -0,0 +1,141 @@
+// Copyright (c) 2021 Red Hat, Inc.
+// Copyright Contributors to the Open Cluster Management project
+
+package tests
+
+import (
+	"fmt"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/stolostron/observability-e2e-test/pkg/utils"
+)
+
+// driftSpec is a spec changing an object managed by the operators manually and expecting the change to be
+// reverted, the spec is nested in a Context of the context text if it is set
+type driftSpec struct {
+	context string
+	text    string
+	drift   utils.DriftCase
+}
+
+// driftSpecs is the table of the drift specs, the drift coverage of a managed object is a row
+var driftSpecs = []driftSpec{
+	{
+		context: "[P2][Sev2][Observability] Verify metrics collector is prevent to be configured manually (endpoint_preserve/g0) -",
+		text:    "[Stable] Deleting metrics-collector deployment",
+		drift: utils.DriftCase{
+			GVR:       utils.NewDeploymentsGVR(),
+			Namespace: MCO_ADDON_NAMESPACE,
+			Name:      "metrics-collector-deployment",
+			Mutation:  utils.DriftDelete,
+			Restored:  []string{"/spec/template/spec/serviceAccountName"},
+			Timeout:   utils.TimeoutObjectUpdate,
+		},
+	},
+	{
+		context: "[P2][Sev2][Observability] Verify metrics collector is prevent to be configured manually (endpoint_preserve/g0) -",
+		text:    "[Stable] Updating metrics-collector deployment",
+		drift: utils.DriftCase{
+			GVR:       utils.NewDeploymentsGVR(),
+			Namespace: MCO_ADDON_NAMESPACE,
+			Name:      "metrics-collector-deployment",
+			Mutation:  utils.DriftJSONPatch,
+			Patch:     `[{"op": "replace", "path": "/spec/template/spec/serviceAccountName", "value": "test-serviceaccount"}]`,
+			Restored:  []string{"/spec/template/spec/serviceAccountName"},
+			Timeout:   utils.TimeoutObjectUpdate,
+		},
+	},
+	{
+		text: "[P2][Sev2][Observability][Stable] Verify metrics collector is prevent to be configured manually - Should recreate metrics-collector-view clusterolebinding if deleted (endpoint_preserve/g0)",
+		drift: utils.DriftCase{
+			GVR:      utils.NewClusterRoleBindingsGVR(),
+			Name:     "metrics-collector-view",
+			Mutation: utils.DriftDelete,
+			Restored: []string{"/roleRef/name", "/subjects"},
+			Timeout:  utils.TimeoutObjectUpdate,
+		},
+	},
+	{
+		text: "[P2][Sev2][Observability][Stable] Verify metrics collector is prevent to be configured manually - Should revert any manual changes on metrics-collector-view clusterolebinding (endpoint_preserve/g0)",
+		drift: utils.DriftCase{
+			GVR:      utils.NewClusterRoleBindingsGVR(),
+			Name:     "metrics-collector-view",
+			Mutation: utils.DriftJSONPatch,
+			Patch:    `[{"op": "replace", "path": "/subjects/0/name", "value": "test-subject"}]`,
+			Restored: []string{"/subjects/0/name"},
+			Timeout:  utils.TimeoutObjectUpdate,
+		},
+	},
+	{
+		text: "[P2][Sev2][Observability][Stable] Verify metrics collector is prevent to be configured manually - Should recreate on metrics-collector-serving-certs-ca-bundle configmap if deleted (endpoint_preserve/g0)",
+		drift: utils.DriftCase{
+			GVR:       utils.NewConfigMapsGVR(),
+			Namespace: MCO_ADDON_NAMESPACE,
+			Name:      "metrics-collector-serving-certs-ca-bundle",
+			Mutation:  utils.DriftDelete,
+			Timeout:   utils.TimeoutObjectUpdate,
+		},
+	},
+	{
+		context: "[P1][Sev1][Observability] Verify Observatorium CR configuration compliance (observatorium_preserve/g0) -",
+		text:    "[Stable] Updating observatorium cr (spec.thanos.compact.retentionResolution1h) should be automatically reverted",
+		drift: utils.DriftCase{
+			GVR:            utils.NewMCOMObservatoriumGVR(),
+			Namespace:      MCO_NAMESPACE,
+			Name:           MCO_CR_NAME,
+			Hub:            true,
+			Mutation:       utils.DriftJSONPatch,
+			Patch:          `[{"op": "replace", "path": "/spec/thanos/compact/retentionResolution1h", "value": "10d"}]`,
+			Restored:       []string{"/spec/thanos/compact/retentionResolution1h"},
+			Components:     []string{"thanos-compact"},
+			Timeout:        utils.TimeoutReconcile,
+			RolloutTimeout: utils.TimeoutRolloutRestart,
+		},
+	},
+}
+
+var _ = Describe("Observability:", func() {
+	BeforeEach(func() {
+		hubClient = utils.NewKubeClient(
+			testOptions.HubCluster.MasterURL,
+			testOptions.KubeConfig,
+			testOptions.HubCluster.KubeContext)
+
+		dynClient = utils.NewKubeClientDynamic(
+			testOptions.HubCluster.MasterURL,
+			testOptions.KubeConfig,
+			testOptions.HubCluster.KubeContext)
+	})
+
+	// the specs keep the names they had before the table, so the results can be compared across the runs
+	for _, spec := range driftSpecs {
+		spec := spec
+		it := func() {
+			It(spec.text, func() {
+				await(fmt.Sprintf("Waiting for the %s of %s to be reverted", spec.drift.Mutation, spec.drift), func() error {
+					return utils.CheckDriftReverted(testOptions, spec.drift)
+				})
+			})
+		}
+		if spec.context == "" {
+			it()
+		} else {
+			Context(spec.context, it)
+		}
+	}
+
+	JustAfterEach(func() {
+		Expect(utils.IntegrityChecking(testOptions)).NotTo(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		if CurrentGinkgoTestDescription().Failed {
+			utils.PrintMCOObject(testOptions)
+			utils.PrintAllMCOPodsStatus(testOptions)
+			utils.PrintAllOBAPodsStatus(testOptions)
+		}
+		testFailed = testFailed || CurrentGinkgoTestDescription().Failed
+	})
+})
@@ -13,6 +13,7 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	"github.com/stolostron/observability-e2e-test/pkg/kustomize"
+	"github.com/stolostron/observability-e2e-test/pkg/reporters"
 	"github.com/stolostron/observability-e2e-test/pkg/utils"
 )
 
@@ -49,7 +50,7 @@ func installMCO() {
 	// print mco logs if MCO installation failed
 	defer func(testOptions utils.TestOptions, isHub bool, namespace, podName, containerName string, previous bool, tailLines int64) {
 		if testFailed {
-			mcoLogs, err := utils.GetPodLogs(testOptions, isHub, namespace, podName, containerName, previous, tailLines)
+			mcoLogs, err := utils.GetPodLogs(testOptions, isHub, namespace, podName, containerName, previous, tailLines, time.Time{})
 			Expect(err).NotTo(HaveOccurred())
 			fmt.Fprintf(GinkgoWriter, "[DEBUG] MCO is installed failed, checking MCO operator logs:\n%s\n", mcoLogs)
 		} else {
@@ -82,7 +83,9 @@ func installMCO() {
 		Expect(utils.CreateMCOTestingRBAC(testOptions)).NotTo(HaveOccurred())
 	}
 
-	if os.Getenv("SKIP_INTEGRATION_CASES") != "true" {
+	// the v1beta2 MCO CR changes the retention config of the v1beta1 one
+	var compactTracker *utils.RolloutTracker
+	if specFilter.AllowsTier(reporters.TierIntegration) {
 		By("Creating MCO instance of v1beta1")
 		v1beta1KustomizationPath := "../../observability-gitops/mco/e2e/v1beta1"
 		yamlB, err = kustomize.Render(kustomize.Options{KustomizationPath: v1beta1KustomizationPath})
@@ -90,23 +93,10 @@ func installMCO() {
 		Expect(utils.Apply(testOptions.HubCluster.MasterURL, testOptions.KubeConfig, testOptions.HubCluster.KubeContext, yamlB)).NotTo(HaveOccurred())
 
 		By("Waiting for MCO ready status")
-		allPodsIsReady := false
-		Eventually(func() error {
-			instance, err := dynClient.Resource(utils.NewMCOGVRV1BETA1()).Get(MCO_CR_NAME, metav1.GetOptions{})
-			if err == nil {
-				allPodsIsReady = utils.StatusContainsTypeEqualTo(instance, "Ready")
-				if allPodsIsReady {
-					testFailed = false
-					return nil
-				}
-			}
-			testFailed = true
-			if instance != nil && instance.Object != nil {
-				return fmt.Errorf("MCO componnets cannot be running in 20 minutes. check the MCO CR status for the details: %v", instance.Object["status"])
-			} else {
-				return fmt.Errorf("Wait for reconciling.")
-			}
-		}, EventuallyTimeoutMinute*20, EventuallyIntervalSecond*5).Should(Succeed())
+		err = utils.WaitForCondition(testOptions, true, utils.NewMCOGVRV1BETA1(), "", MCO_CR_NAME, "Ready", "True",
+			timeout(utils.TimeoutMCOInstall))
+		testFailed = err != nil
+		Expect(err).NotTo(HaveOccurred())
 
 		By("Check clustermanagementaddon CR is created")
 		Eventually(func() error {
@@ -123,6 +113,9 @@ func installMCO() {
 		v1beta1Tov1beta2GoldenPath := "../../observability-gitops/mco/e2e/v1beta1/observability-v1beta1-to-v1beta2-golden.yaml"
 		err = utils.CheckMCOConversion(testOptions, v1beta1Tov1beta2GoldenPath)
 		Expect(err).NotTo(HaveOccurred())
+
+		compactTracker, err = utils.NewComponentRolloutTracker(testOptions, "thanos-compact")
+		Expect(err).NotTo(HaveOccurred())
 	}
 
 	By("Apply MCO instance of v1beta2")
@@ -133,13 +126,27 @@ func installMCO() {
 	// add retry for update mco object failure
 	Eventually(func() error {
 		return utils.Apply(testOptions.HubCluster.MasterURL, testOptions.KubeConfig, testOptions.HubCluster.KubeContext, yamlB)
-	}, EventuallyTimeoutMinute*5, EventuallyIntervalSecond*5).Should(Succeed())
+	}, timeout(utils.TimeoutReconcile), EventuallyIntervalSecond*5).Should(Succeed())
 
-	// wait for pod restarting
-	time.Sleep(60 * time.Second)
+	if os.Getenv("IS_CANARY_ENV") == "true" {
+		// mount the CA certificate of the object storage referenced by the ca_file of the secret
+		Eventually(func() error {
+			return utils.ModifyMCOObjStorageTLS(testOptions)
+		}, timeout(utils.TimeoutReconcile), EventuallyIntervalSecond*5).Should(Succeed())
+	}
 
-	By("Waiting for MCO ready status")
-	Eventually(func() error {
+	// the components restart with the v1beta2 config, the rollout of thanos-compact proves the operators
+	// reconciled the v1beta2 MCO CR before the rollout of all components is checked
+	if compactTracker != nil {
+		await("Waiting for thanos-compact to roll out the v1beta2 config", func() error {
+			return compactTracker.Wait(timeout(utils.TimeoutRolloutRestart))
+		})
+	}
+	eventually("Waiting for the MCO components to roll out", func() error {
+		return utils.CheckMCOComponentsRolledOut(testOptions)
+	}, timeout(utils.TimeoutMCOReady), EventuallyIntervalSecond*10).Should(Succeed())
+
+	eventually("Waiting for MCO ready status", func() error {
 		err = utils.CheckMCOComponents(testOptions)
 		if err != nil {
 			testFailed = true
@@ -148,7 +155,7 @@ func installMCO() {
 		}
 		testFailed = false
 		return nil
-	}, EventuallyTimeoutMinute*25, EventuallyIntervalSecond*10).Should(Succeed())
+	}, timeout(utils.TimeoutMCOReady), EventuallyIntervalSecond*10).Should(Succeed())
 
 	By("Checking placementrule CR is created")
 	Eventually(func() error {
@@ -159,7 +166,7 @@ func installMCO() {
 		}
 		testFailed = false
 		return nil
-	}, EventuallyTimeoutMinute*10, EventuallyIntervalSecond*5).Should(Succeed())
+	}, timeout(utils.TimeoutResourceReady), EventuallyIntervalSecond*5).Should(Succeed())
 
 	if os.Getenv("IS_CANARY_ENV") != "true" {
 		// TODO(morvencao): remove the patch from placement is implemented by server foundation.
@@ -187,7 +194,7 @@ func installMCO() {
 		}
 		testFailed = false
 		return nil
-	}, EventuallyTimeoutMinute*5, EventuallyIntervalSecond*5).Should(Succeed())
+	}, timeout(utils.TimeoutResourceReady), EventuallyIntervalSecond*5).Should(Succeed())
 
 	By("Check clustermanagementaddon CR is created")
 	Eventually(func() error {
@@ -0,0 +1,86 @@
+// Copyright (c) 2021 Red Hat, Inc.
+// Copyright Contributors to the Open Cluster Management project
+
+package tests
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"k8s.io/klog"
+
+	"github.com/stolostron/observability-e2e-test/pkg/reporters"
+	"github.com/stolostron/observability-e2e-test/pkg/utils"
+)
+
+const (
+	ingestionLatencyMetric           = "node_memory_MemAvailable_bytes"
+	defaultIngestionLatencyThreshold = 5 * time.Minute
+	defaultIngestionLatencyDuration  = 5 * time.Minute
+)
+
+var _ = Describe("Observability:", func() {
+	BeforeEach(func() {
+		hubClient = utils.NewKubeClient(
+			testOptions.HubCluster.MasterURL,
+			testOptions.KubeConfig,
+			testOptions.HubCluster.KubeContext)
+
+		dynClient = utils.NewKubeClientDynamic(
+			testOptions.HubCluster.MasterURL,
+			testOptions.KubeConfig,
+			testOptions.HubCluster.KubeContext)
+	})
+
+	It("[P2][Sev2][Observability][Stable] Should ingest metrics from managed clusters within the latency threshold (latency/g1)", func() {
+		threshold := defaultIngestionLatencyThreshold
+		if testOptions.IngestionLatencyThreshold != "" {
+			d, err := time.ParseDuration(testOptions.IngestionLatencyThreshold)
+			Expect(err).NotTo(HaveOccurred())
+			threshold = d
+		}
+		duration := defaultIngestionLatencyDuration
+		if testOptions.IngestionLatencyDuration != "" {
+			d, err := time.ParseDuration(testOptions.IngestionLatencyDuration)
+			Expect(err).NotTo(HaveOccurred())
+			duration = d
+		}
+
+		clusters, err := utils.ListManagedClusterNames(testOptions)
+		Expect(err).NotTo(HaveOccurred())
+		if len(clusters) == 0 {
+			Skip("Skip the case since there is no managed cluster with observability enabled")
+		}
+
+		By("Measuring the ingestion latency of " + ingestionLatencyMetric + " for " + duration.String())
+		latencies, err := utils.MeasureIngestionLatency(testOptions, clusters, ingestionLatencyMetric, duration, EventuallyIntervalSecond*5)
+		Expect(err).NotTo(HaveOccurred())
+
+		for _, cluster := range clusters {
+			latency := latencies[cluster]
+			klog.V(1).Infof("Ingestion latency of cluster %s: p50=%v, p95=%v, max=%v", cluster, latency.Quantile(0.5), latency.Quantile(0.95), latency.Max())
+			reporters.RecordProperty("ingestion-latency-p50/"+cluster, latency.Quantile(0.5))
+			reporters.RecordProperty("ingestion-latency-p95/"+cluster, latency.Quantile(0.95))
+			reporters.RecordProperty("ingestion-latency-max/"+cluster, latency.Max())
+		}
+
+		By("Checking the ingestion latency is less than " + threshold.String())
+		for _, cluster := range clusters {
+			Expect(latencies[cluster].Max()).To(BeNumerically("<=", threshold), "ingestion latency of cluster %s exceeds the threshold", cluster)
+		}
+	})
+
+	JustAfterEach(func() {
+		Expect(utils.IntegrityChecking(testOptions)).NotTo(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		if CurrentGinkgoTestDescription().Failed {
+			utils.PrintMCOObject(testOptions)
+			utils.PrintAllMCOPodsStatus(testOptions)
+			utils.PrintAllOBAPodsStatus(testOptions)
+		}
+		testFailed = testFailed || CurrentGinkgoTestDescription().Failed
+	})
+})
@@ -5,6 +5,7 @@ package tests
 
 import (
 	"errors"
+	"time"
 
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
@@ -28,11 +29,24 @@ var _ = Describe("Observability:", func() {
 
 	Context("[P2][Sev2][Observability][Stable] Should be automatically created within 1 minute when delete manifestwork (manifestwork/g0) -", func() {
 		manifestWorkName := "endpoint-observability-work"
-		clientDynamic := utils.GetKubeClientDynamic(testOptions, true)
-		clusterName := utils.GetManagedClusterName(testOptions)
-		if clusterName != "" {
+		var (
+			clusterName         string
+			oldCollectorPodName string
+			deletedAt           time.Time
+		)
+		BeforeEach(func() {
+			// the options are loaded after the tree is built
+			clusterName = utils.GetManagedClusterName(testOptions)
+			if clusterName == "" {
+				Skip("Skip the case since there is no managed cluster")
+			}
+			// the work agent and the addon components log errors while the manifestwork is recreated
+			logScanner.AllowBursts()
+		})
+
+		It("[Stable] Deleting manifestwork and waiting for it to be created automatically", func() {
+			clientDynamic := utils.GetKubeClientDynamic(testOptions, true)
 			oldManifestWorkResourceVersion := ""
-			oldCollectorPodName := ""
 			_, podList := utils.GetPodList(testOptions, false, MCO_ADDON_NAMESPACE, "component=metrics-collector")
 			if podList != nil && len(podList.Items) > 0 {
 				oldCollectorPodName = podList.Items[0].Name
@@ -40,18 +54,21 @@ var _ = Describe("Observability:", func() {
 
 			Eventually(func() error {
 				oldManifestWork, err := clientDynamic.Resource(utils.NewOCMManifestworksGVR()).Namespace(clusterName).Get(manifestWorkName, metav1.GetOptions{})
+				if err != nil {
+					return err
+				}
 				oldManifestWorkResourceVersion = oldManifestWork.GetResourceVersion()
-				return err
-			}, EventuallyTimeoutMinute*1, EventuallyIntervalSecond*5).Should(Succeed())
+				return nil
+			}, timeout(utils.TimeoutObjectUpdate), EventuallyIntervalSecond*5).Should(Succeed())
 
 			By("Waiting for manifestwork to be deleted")
+			deletedAt = time.Now()
 			Eventually(func() error {
 				err := clientDynamic.Resource(utils.NewOCMManifestworksGVR()).Namespace(clusterName).Delete(manifestWorkName, &metav1.DeleteOptions{})
 				return err
-			}, EventuallyTimeoutMinute*1, EventuallyIntervalSecond*5).Should(Succeed())
+			}, timeout(utils.TimeoutObjectUpdate), EventuallyIntervalSecond*5).Should(Succeed())
 
-			By("Waiting for manifestwork to be created automatically")
-			Eventually(func() error {
+			eventually("Waiting for manifestwork to be created automatically", func() error {
 				newManifestWork, err := clientDynamic.Resource(utils.NewOCMManifestworksGVR()).Namespace(clusterName).Get(manifestWorkName, metav1.GetOptions{})
 				if err == nil {
 					if newManifestWork.GetResourceVersion() != oldManifestWorkResourceVersion {
@@ -62,37 +79,47 @@ var _ = Describe("Observability:", func() {
 				} else {
 					return err
 				}
-			}, EventuallyTimeoutMinute*2, EventuallyIntervalSecond*5).Should(Succeed())
-
-			It("[Stable] Waiting for metrics collector to be created automatically", func() {
-				Eventually(func() error {
-					_, podList := utils.GetPodList(testOptions, false, MCO_ADDON_NAMESPACE, "component=metrics-collector")
-					if podList != nil && len(podList.Items) > 0 {
-						if oldCollectorPodName != podList.Items[0].Name {
-							return nil
-						}
-					}
-					return errors.New("No new metrics collector generated")
-				}, EventuallyTimeoutMinute*5, EventuallyIntervalSecond*5).Should(Succeed())
-			})
-
-			It("[Stable] Checking OBA components are ready", func() {
-				Eventually(func() error {
-					err = utils.CheckOBAComponents(testOptions)
-					if err != nil {
-						return err
+			}, timeout(utils.TimeoutManifestWorkRecreate), EventuallyIntervalSecond*5).Should(Succeed())
+		})
+
+		It("[Stable] Waiting for metrics collector to be created automatically", func() {
+			Eventually(func() error {
+				_, podList := utils.GetPodList(testOptions, false, MCO_ADDON_NAMESPACE, "component=metrics-collector")
+				if podList != nil && len(podList.Items) > 0 {
+					if oldCollectorPodName != podList.Items[0].Name {
+						return nil
 					}
-					return nil
-				}, EventuallyTimeoutMinute*3, EventuallyIntervalSecond*5).Should(Succeed())
-			})
+				}
+				return errors.New("No new metrics collector generated")
+			}, timeout(utils.TimeoutResourceReady), EventuallyIntervalSecond*5).Should(Succeed())
+		})
 
-			It("[Stable] Checking metric to ensure that no data is lost in 1 minute", func() {
-				Eventually(func() error {
-					err, _ = utils.ContainManagedClusterMetric(testOptions, `timestamp(node_memory_MemAvailable_bytes{cluster="`+clusterName+`}) - timestamp(node_memory_MemAvailable_bytes{cluster=`+clusterName+`"} offset 1m) > 59`, []string{`"__name__":"node_memory_MemAvailable_bytes"`})
+		It("[Stable] Checking OBA components are ready", func() {
+			Eventually(func() error {
+				return utils.CheckOBAComponents(testOptions)
+			}, timeout(utils.TimeoutReconcile), EventuallyIntervalSecond*5).Should(Succeed())
+		})
+
+		It("[Stable] Checking the manifests are applied on the managed cluster", func() {
+			eventually("Waiting for the manifests of the manifestwork to be applied", func() error {
+				report, err := utils.VerifyManifestWork(testOptions, clusterName, manifestWorkName)
+				if err != nil {
 					return err
-				}, EventuallyTimeoutMinute*1, EventuallyIntervalSecond*3).Should(Succeed())
-			})
-		}
+				}
+				return report.Error()
+			}, timeout(utils.TimeoutReconcile), EventuallyIntervalSecond*5).Should(Succeed())
+		})
+
+		It("[Stable] Checking metric to ensure that no data is lost in 1 minute", func() {
+			if deletedAt.IsZero() {
+				Skip("Skip the case since the manifestwork is not deleted")
+			}
+			checkedAt := time.Now()
+			Eventually(func() error {
+				return utils.CheckManagedClusterMetricContinuity(testOptions, "node_memory_MemAvailable_bytes", []string{clusterName},
+					deletedAt.Add(-2*time.Minute), checkedAt, 3)
+			}, timeout(utils.TimeoutReconcile), EventuallyIntervalSecond*10).Should(Succeed())
+		})
 	})
 
 	JustAfterEach(func() {
@@ -27,6 +27,8 @@ var _ = Describe("Observability:", func() {
 			testOptions.HubCluster.MasterURL,
 			testOptions.KubeConfig,
 			testOptions.HubCluster.KubeContext)
+		// the metrics collector logs errors while the custom allowlist is changed
+		logScanner.AllowBursts()
 	})
 
 	It("[P2][Sev2][Observability][Integration] Customized metrics data are collected (metricslist/g0)", func() {
@@ -35,27 +37,57 @@ var _ = Describe("Observability:", func() {
 		Expect(err).ToNot(HaveOccurred())
 		Expect(utils.Apply(testOptions.HubCluster.MasterURL, testOptions.KubeConfig, testOptions.HubCluster.KubeContext, yamlB)).NotTo(HaveOccurred())
 
-		By("Waiting for new added metrics on grafana console")
-		Eventually(func() error {
+		eventually("Waiting for new added metrics on grafana console", func() error {
 			err, _ := utils.ContainManagedClusterMetric(testOptions, "node_memory_Active_bytes offset 1m", []string{`"__name__":"node_memory_Active_bytes"`})
 			return err
-		}, EventuallyTimeoutMinute*10, EventuallyIntervalSecond*5).Should(Succeed())
+		}, timeout(utils.TimeoutMetricVisible), EventuallyIntervalSecond*5).Should(Succeed())
 	})
 
-	It("[P2][Sev2][Observability][Integration] Metrics removal from default allowlist (metricslist/g0)", func() {
-		By("Waiting for deleted metrics disappear on grafana console")
+	It("[P2][Sev2][Observability][Integration] Only allowlisted metrics are collected (metricslist/g0)", func() {
+		clusters, err := utils.ListManagedClusterNames(testOptions)
+		Expect(err).NotTo(HaveOccurred())
+		if len(clusters) == 0 {
+			Skip("Skip the case since there is no managed cluster with observability enabled")
+		}
+
+		By("Parsing the default and custom metrics allowlist configmaps")
+		defaultAllowlist, err := utils.GetMetricsAllowlist(testOptions, true, utils.AllowlistConfigMapName, MCO_NAMESPACE)
+		Expect(err).NotTo(HaveOccurred())
+		customAllowlist, err := utils.GetMetricsAllowlist(testOptions, true, allowlistCMname, MCO_NAMESPACE)
+		Expect(err).NotTo(HaveOccurred())
+		allowlist := utils.MergeMetricsAllowlist(defaultAllowlist, customAllowlist)
+
+		ignoredNames, err := utils.GetThanosRuleRecordNames(testOptions)
+		Expect(err).NotTo(HaveOccurred())
+		ignoredNames = append(ignoredNames, "ALERTS", "ALERTS_FOR_STATE")
+
+		By("Checking the metrics of each managed cluster against the allowlist")
 		Eventually(func() error {
+			reports, err := utils.VerifyMetricsAllowlist(testOptions, allowlist, clusters, ignoredNames)
+			if err != nil {
+				return err
+			}
+			for _, report := range reports {
+				if err := report.Error(); err != nil {
+					return err
+				}
+			}
+			return nil
+		}, timeout(utils.TimeoutMetricVisible), EventuallyIntervalSecond*30).Should(Succeed())
+	})
+
+	It("[P2][Sev2][Observability][Integration] Metrics removal from default allowlist (metricslist/g0)", func() {
+		eventually("Waiting for deleted metrics disappear on grafana console", func() error {
 			err, _ := utils.ContainManagedClusterMetric(testOptions, "timestamp(cluster_version_payload) - timestamp(cluster_version_payload offset 1m) > 59", []string{})
 			return err
-		}, EventuallyTimeoutMinute*10, EventuallyIntervalSecond*5).Should(MatchError("Failed to find metric name from response"))
+		}, timeout(utils.TimeoutMetricGone), EventuallyIntervalSecond*5).Should(MatchError("Failed to find metric name from response"))
 	})
 
 	It("[P2][Sev2][Observability][Integration] Metrics removal from default allowlist (metricslist/g0)", func() {
-		By("Waiting for deleted metrics disappear on grafana console")
-		Eventually(func() error {
+		eventually("Waiting for deleted metrics disappear on grafana console", func() error {
 			err, _ := utils.ContainManagedClusterMetric(testOptions, "timestamp(go_goroutines) - timestamp(go_goroutines offset 1m) > 59", []string{})
 			return err
-		}, EventuallyTimeoutMinute*10, EventuallyIntervalSecond*5).Should(MatchError("Failed to find metric name from response"))
+		}, timeout(utils.TimeoutMetricGone), EventuallyIntervalSecond*5).Should(MatchError("Failed to find metric name from response"))
 	})
 
 	It("[P2][Sev2][Observability][Integration] Metrics removal from default allowlist (metricslist/g0)", func() {
@@ -63,13 +95,12 @@ var _ = Describe("Observability:", func() {
 		Eventually(func() error {
 			err := hubClient.CoreV1().ConfigMaps(MCO_NAMESPACE).Delete(allowlistCMname, &metav1.DeleteOptions{})
 			return err
-		}, EventuallyTimeoutMinute*1, EventuallyIntervalSecond*1).Should(Succeed())
+		}, timeout(utils.TimeoutObjectUpdate), EventuallyIntervalSecond*1).Should(Succeed())
 
-		By("Waiting for new added metrics disappear on grafana console")
-		Eventually(func() error {
+		eventually("Waiting for new added metrics disappear on grafana console", func() error {
 			err, _ := utils.ContainManagedClusterMetric(testOptions, "timestamp(node_memory_Active_bytes) - timestamp(node_memory_Active_bytes offset 1m) > 59", []string{})
 			return err
-		}, EventuallyTimeoutMinute*10, EventuallyIntervalSecond*5).Should(MatchError("Failed to find metric name from response"))
+		}, timeout(utils.TimeoutMetricGone), EventuallyIntervalSecond*5).Should(MatchError("Failed to find metric name from response"))
 	})
 
 	JustAfterEach(func() {
@@ -0,0 +1,90 @@
+// Copyright (c) 2021 Red Hat, Inc.
+// Copyright Contributors to the Open Cluster Management project
+
+package tests
+
+import (
+	"crypto/x509"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/stolostron/observability-e2e-test/pkg/utils"
+	"github.com/stolostron/observability-e2e-test/pkg/utils/certs"
+)
+
+var _ = Describe("Observability:", func() {
+	var (
+		endpoint string
+		roots    *x509.CertPool
+	)
+
+	BeforeEach(func() {
+		hubClient = utils.NewKubeClient(
+			testOptions.HubCluster.MasterURL,
+			testOptions.KubeConfig,
+			testOptions.HubCluster.KubeContext)
+
+		dynClient = utils.NewKubeClientDynamic(
+			testOptions.HubCluster.MasterURL,
+			testOptions.KubeConfig,
+			testOptions.HubCluster.KubeContext)
+
+		endpoint = utils.GetObservatoriumAPIWriteURL(testOptions)
+		var err error
+		roots, err = certs.GetObservatoriumAPIRootCAs(testOptions)
+		Expect(err).NotTo(HaveOccurred())
+		// observatorium-api logs the rejected client certificates
+		Expect(logScanner.Allow("TLS handshake error")).To(Succeed())
+	})
+
+	It("[P1][Sev1][Observability][Integration] Should accept the managed cluster client certificate (mtls/g0)", func() {
+		clientCert, err := certs.LoadKeyPair(testOptions, false, utils.ManagedClusterClientCerts, MCO_ADDON_NAMESPACE)
+		Expect(err).NotTo(HaveOccurred())
+
+		By("Probing " + endpoint + " with the managed cluster client certificate")
+		Eventually(func() bool {
+			return certs.ProbeObservatoriumAPI(endpoint, roots, clientCert).Accepted()
+		}, timeout(utils.TimeoutObjectUpdate), EventuallyIntervalSecond*5).Should(BeTrue())
+	})
+
+	It("[P1][Sev1][Observability][Integration] Should reject the expired client certificate (mtls/g0)", func() {
+		clientCA, err := certs.LoadKeyPair(testOptions, true, utils.ClientCACerts, MCO_NAMESPACE)
+		Expect(err).NotTo(HaveOccurred())
+		clientCert, err := certs.NewClientCert("e2e-expired", clientCA, time.Now().Add(-48*time.Hour), time.Now().Add(-24*time.Hour))
+		Expect(err).NotTo(HaveOccurred())
+
+		By("Probing " + endpoint + " with the expired client certificate")
+		result := certs.ProbeObservatoriumAPI(endpoint, roots, clientCert)
+		Expect(result.RejectedAs(certs.RejectionExpiredCert)).To(BeTrue(), "expected the request to be rejected as %v, got %s", certs.RejectionExpiredCert, result)
+	})
+
+	It("[P1][Sev1][Observability][Integration] Should reject the client certificate signed by a foreign CA (mtls/g0)", func() {
+		clientCert, err := certs.NewClientCert("e2e-foreign", nil, time.Now().Add(-time.Hour), time.Now().Add(24*time.Hour))
+		Expect(err).NotTo(HaveOccurred())
+
+		By("Probing " + endpoint + " with the self-signed client certificate")
+		result := certs.ProbeObservatoriumAPI(endpoint, roots, clientCert)
+		Expect(result.RejectedAs(certs.RejectionUnknownCA)).To(BeTrue(), "expected the request to be rejected as %v, got %s", certs.RejectionUnknownCA, result)
+	})
+
+	It("[P1][Sev1][Observability][Integration] Should reject the request without client certificate (mtls/g0)", func() {
+		By("Probing " + endpoint + " without client certificate")
+		result := certs.ProbeObservatoriumAPI(endpoint, roots, nil)
+		Expect(result.RejectedAs(certs.RejectionNoCert)).To(BeTrue(), "expected the request to be rejected as %v, got %s", certs.RejectionNoCert, result)
+	})
+
+	JustAfterEach(func() {
+		Expect(utils.IntegrityChecking(testOptions)).NotTo(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		if CurrentGinkgoTestDescription().Failed {
+			utils.PrintMCOObject(testOptions)
+			utils.PrintAllMCOPodsStatus(testOptions)
+			utils.PrintAllOBAPodsStatus(testOptions)
+		}
+		testFailed = testFailed || CurrentGinkgoTestDescription().Failed
+	})
+})
@@ -42,6 +42,7 @@ var _ = Describe("Observability:", func() {
 
 	It("[P2][Sev2][Observability][Stable] Check and tune backup retention settings in MCO CR - tune retention settings in MCO CR (reconcile/g0)", func() {
 		By("Modifying MCO CR for reconciling")
+		Expect(restartMonitor.ExpectComponentRestarts("thanos-compact", "thanos-rule", "alertmanager")).To(Succeed())
 		err := utils.ModifyMCOCR(testOptions)
 		Expect(err).ToNot(HaveOccurred())
 
@@ -64,37 +65,29 @@ var _ = Describe("Observability:", func() {
 				}
 			}
 			return fmt.Errorf("Failed to find modified retention field, the current args is: %v", argList)
-		}, EventuallyTimeoutMinute*5, EventuallyIntervalSecond*5).Should(Succeed())
+		}, timeout(utils.TimeoutReconcile), EventuallyIntervalSecond*5).Should(Succeed())
 
-		By("Wait for thanos compact pods are ready")
 		compacts, _ := hubClient.AppsV1().StatefulSets(MCO_NAMESPACE).List(metav1.ListOptions{
 			LabelSelector: THANOS_COMPACT_LABEL,
 		})
 		Expect(len(compacts.Items)).NotTo(Equal(0))
 
 		// ensure the thanos rule pods are restarted successfully before processing
-		Eventually(func() error {
-			err = utils.CheckStatefulSetPodReady(testOptions, (*compacts).Items[0].Name)
-			if err != nil {
-				return err
-			}
-			return nil
-		}, EventuallyTimeoutMinute*10, EventuallyIntervalSecond*5).Should(Succeed())
+		await("Wait for thanos compact pods are ready", func() error {
+			return utils.WaitForRollout(testOptions, true, utils.KindStatefulSet, (*compacts).Items[0].Name, MCO_NAMESPACE,
+				timeout(utils.TimeoutRolloutRestart))
+		})
 
-		By("Wait for alertmanager pods are ready")
 		// ensure the thanos rule pods are restarted successfully before processing
 		alertmans, _ := hubClient.AppsV1().StatefulSets(MCO_NAMESPACE).List(metav1.ListOptions{
 			LabelSelector: ALERTMANAGER_LABEL,
 		})
 		Expect(len(alertmans.Items)).NotTo(Equal(0))
 
-		Eventually(func() error {
-			err = utils.CheckStatefulSetPodReady(testOptions, (*alertmans).Items[0].Name)
-			if err != nil {
-				return err
-			}
-			return nil
-		}, EventuallyTimeoutMinute*10, EventuallyIntervalSecond*5).Should(Succeed())
+		await("Wait for alertmanager pods are ready", func() error {
+			return utils.WaitForRollout(testOptions, true, utils.KindStatefulSet, (*alertmans).Items[0].Name, MCO_NAMESPACE,
+				timeout(utils.TimeoutRolloutRestart))
+		})
 	})
 
 	It("[P2][Sev2][Observability][Stable] Verify nodeSelector setting effects for Observability components (reconcile/g0)", func() {
@@ -114,7 +107,7 @@ var _ = Describe("Observability:", func() {
 				return err
 			}
 			return nil
-		}, EventuallyTimeoutMinute*5, EventuallyIntervalSecond*5).Should(Succeed())
+		}, timeout(utils.TimeoutRolloutRestart), EventuallyIntervalSecond*5).Should(Succeed())
 	})
 
 	It("[P2][Sev2][Observability][Stable] Check affinity rule takes effect on Observability components (reconcile/g0)", func() {
@@ -125,23 +118,17 @@ var _ = Describe("Observability:", func() {
 				return err
 			}
 			return nil
-		}, EventuallyTimeoutMinute*5, EventuallyIntervalSecond*5).Should(Succeed())
+		}, timeout(utils.TimeoutRolloutRestart), EventuallyIntervalSecond*5).Should(Succeed())
 	})
 
 	It("[P2][Sev2][Observability][Stable] Customize the Observability components storage size (reconcile/g0)", func() {
 		By("Resizing alertmanager storage")
-		alertmans, _ := hubClient.AppsV1().StatefulSets(MCO_NAMESPACE).List(metav1.ListOptions{
-			LabelSelector: ALERTMANAGER_LABEL,
-		})
-		Expect(len(alertmans.Items)).NotTo(Equal(0))
+		alertmanager, err := utils.GetComponent("alertmanager")
+		Expect(err).NotTo(HaveOccurred())
 
 		Eventually(func() error {
-			err := utils.CheckStorageResize(testOptions, (*alertmans).Items[0].Name, "2Gi")
-			if err != nil {
-				return err
-			}
-			return nil
-		}, EventuallyTimeoutMinute*5, EventuallyIntervalSecond*5).Should(Succeed())
+			return alertmanager.CheckStorageSize(testOptions, "2Gi")
+		}, timeout(utils.TimeoutRolloutRestart), EventuallyIntervalSecond*5).Should(Succeed())
 	})
 
 	It("[P2][Sev2][Observability][Stable] Check and tune backup retention settings in MCO CR - Revert MCO CR changes (reconcile/g0)", func() {
@@ -151,11 +138,11 @@ var _ = Describe("Observability:", func() {
 		}
 
 		By("Revert MCO CR changes")
+		Expect(restartMonitor.ExpectComponentRestarts("thanos-compact", "thanos-rule", "alertmanager")).To(Succeed())
 		err = utils.RevertMCOCRModification(testOptions)
 		Expect(err).ToNot(HaveOccurred())
 
-		By("Waiting for MCO retentionResolutionRaw filed to take effect")
-		Eventually(func() error {
+		eventually("Waiting for MCO retentionResolutionRaw filed to take effect", func() error {
 			compacts, _ := hubClient.AppsV1().StatefulSets(MCO_NAMESPACE).List(metav1.ListOptions{
 				LabelSelector: THANOS_COMPACT_LABEL,
 			})
@@ -168,22 +155,18 @@ var _ = Describe("Observability:", func() {
 				}
 			}
 			return fmt.Errorf("Failed to find modified retention field, the current args is: %v", argList)
-		}, EventuallyTimeoutMinute*5, EventuallyIntervalSecond*5).Should(Succeed())
+		}, timeout(utils.TimeoutReconcile), EventuallyIntervalSecond*5).Should(Succeed())
 
-		By("Wait for thanos compact pods are ready")
 		// ensure the thanos rule pods are restarted successfully before processing
 		compacts, _ := hubClient.AppsV1().StatefulSets(MCO_NAMESPACE).List(metav1.ListOptions{
 			LabelSelector: THANOS_COMPACT_LABEL,
 		})
 		Expect(len(compacts.Items)).NotTo(Equal(0))
 
-		Eventually(func() error {
-			err = utils.CheckStatefulSetPodReady(testOptions, (*compacts).Items[0].Name)
-			if err != nil {
-				return err
-			}
-			return nil
-		}, EventuallyTimeoutMinute*10, EventuallyIntervalSecond*5).Should(Succeed())
+		await("Wait for thanos compact pods are ready", func() error {
+			return utils.WaitForRollout(testOptions, true, utils.KindStatefulSet, (*compacts).Items[0].Name, MCO_NAMESPACE,
+				timeout(utils.TimeoutRolloutRestart))
+		})
 
 		By("Checking MCO components in default HA mode")
 		Eventually(func() error {
@@ -192,7 +175,7 @@ var _ = Describe("Observability:", func() {
 				return err
 			}
 			return nil
-		}, EventuallyTimeoutMinute*15, EventuallyIntervalSecond*5).Should(Succeed())
+		}, timeout(utils.TimeoutAvailabilitySwitch), EventuallyIntervalSecond*5).Should(Succeed())
 	})
 
 	JustAfterEach(func() {
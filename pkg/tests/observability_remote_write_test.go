@@ -0,0 +1,130 @@
+// Copyright (c) 2021 Red Hat, Inc.
+// Copyright Contributors to the Open Cluster Management project
+
+package tests
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/stolostron/observability-e2e-test/pkg/utils"
+)
+
+const (
+	syntheticMetricName = "e2e_synthetic_metric"
+)
+
+var _ = Describe("Observability:", func() {
+	BeforeEach(func() {
+		hubClient = utils.NewKubeClient(
+			testOptions.HubCluster.MasterURL,
+			testOptions.KubeConfig,
+			testOptions.HubCluster.KubeContext)
+
+		dynClient = utils.NewKubeClientDynamic(
+			testOptions.HubCluster.MasterURL,
+			testOptions.KubeConfig,
+			testOptions.HubCluster.KubeContext)
+	})
+
+	// newSyntheticSeries returns a series with three samples one minute apart, labeled with a unique run id
+	newSyntheticSeries := func(runID string) utils.MetricTimeSeries {
+		clusterName := utils.GetManagedClusterName(testOptions)
+		if clusterName == "" {
+			clusterName = utils.LocalClusterName
+		}
+		now := time.Now()
+		return utils.MetricTimeSeries{
+			Labels: map[string]string{
+				"__name__":              syntheticMetricName,
+				"cluster":               clusterName,
+				utils.SyntheticRunLabel: runID,
+			},
+			Samples: []utils.MetricSample{
+				{Timestamp: now.Add(-2 * time.Minute), Value: 1},
+				{Timestamp: now.Add(-1 * time.Minute), Value: 2},
+				{Timestamp: now, Value: 3},
+			},
+		}
+	}
+
+	checkSyntheticSeries := func(series utils.MetricTimeSeries) {
+		query := fmt.Sprintf(`%s{%s="%s"}`, syntheticMetricName, utils.SyntheticRunLabel, series.Labels[utils.SyntheticRunLabel])
+
+		By("Checking the latest synthetic sample has the exact value")
+		Eventually(func() error {
+			result, err := utils.QueryMetric(testOptions, query)
+			if err != nil {
+				return err
+			}
+			if len(result.Data.Result) != 1 {
+				return fmt.Errorf("expected 1 series for %s but got %d", query, len(result.Data.Result))
+			}
+			sample, err := result.Data.Result[0].Sample()
+			if err != nil {
+				return err
+			}
+			if sample.Value != 3 {
+				return fmt.Errorf("expected value 3 for %s but got %v", query, sample.Value)
+			}
+			return nil
+		}, timeout(utils.TimeoutMetricVisible), EventuallyIntervalSecond*5).Should(Succeed())
+
+		By("Checking all synthetic samples are returned by the range query")
+		start := series.Samples[0].Timestamp
+		end := series.Samples[len(series.Samples)-1].Timestamp
+		result, err := utils.QueryMetricRange(testOptions, query, start, end, time.Minute)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.Data.Result).To(HaveLen(1))
+		samples, err := result.Data.Result[0].Samples()
+		Expect(err).NotTo(HaveOccurred())
+		values := []float64{}
+		for _, s := range samples {
+			values = append(values, s.Value)
+		}
+		Expect(values).To(Equal([]float64{1, 2, 3}))
+	}
+
+	It("[P2][Sev2][Observability][Integration] Should query the synthetic metric written through observatorium-api (remotewrite/g0)", func() {
+		series := newSyntheticSeries(StringWithCharset(8, charset))
+
+		By("Writing the synthetic metric with the managed cluster client certificate")
+		Eventually(func() error {
+			return utils.RemoteWriteMetrics(testOptions, []utils.MetricTimeSeries{series})
+		}, timeout(utils.TimeoutObjectUpdate), EventuallyIntervalSecond*5).Should(Succeed())
+
+		checkSyntheticSeries(series)
+	})
+
+	It("[P3][Sev3][Observability][Integration] Should query the synthetic metric written to thanos-receive (remotewrite/g0)", func() {
+		receiveURL := os.Getenv("THANOS_RECEIVE_URL")
+		if receiveURL == "" {
+			Skip("Skip the case since THANOS_RECEIVE_URL is not set")
+		}
+		series := newSyntheticSeries(StringWithCharset(8, charset))
+
+		By("Writing the synthetic metric to thanos-receive")
+		Eventually(func() error {
+			return utils.RemoteWriteMetricsToReceive(receiveURL, []utils.MetricTimeSeries{series})
+		}, timeout(utils.TimeoutObjectUpdate), EventuallyIntervalSecond*5).Should(Succeed())
+
+		checkSyntheticSeries(series)
+	})
+
+	JustAfterEach(func() {
+		Expect(utils.IntegrityChecking(testOptions)).NotTo(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		if CurrentGinkgoTestDescription().Failed {
+			utils.PrintMCOObject(testOptions)
+			utils.PrintAllMCOPodsStatus(testOptions)
+			utils.PrintAllOBAPodsStatus(testOptions)
+		}
+		testFailed = testFailed || CurrentGinkgoTestDescription().Failed
+	})
+})
@@ -6,16 +6,24 @@ package tests
 import (
 	"fmt"
 	"math"
+	"os"
 	"reflect"
 	"strconv"
+	"time"
 
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog"
 
 	"github.com/stolostron/observability-e2e-test/pkg/utils"
 )
 
+const (
+	backfillLabel = "e2e_backfill"
+	backfillQuery = `count(count_over_time({e2e_backfill="true"}[2h]))`
+)
+
 var _ = Describe("Observability:", func() {
 
 	var (
@@ -74,7 +82,7 @@ var _ = Describe("Observability:", func() {
 				}
 			}
 			return fmt.Errorf("Failed to check compact args: --delete-delay="+deleteDelay+". args is %v", argList)
-		}, EventuallyTimeoutMinute*1, EventuallyIntervalSecond*5).Should(Succeed())
+		}, timeout(utils.TimeoutObjectUpdate), EventuallyIntervalSecond*5).Should(Succeed())
 	})
 
 	It("[P2][Sev2][Observability][Stable] Check and tune backup retention settings in MCO CR - Check store args (retention/g0):", func() {
@@ -93,7 +101,7 @@ var _ = Describe("Observability:", func() {
 				}
 			}
 			return fmt.Errorf("Failed to check store args: --ignore-deletion-marks-delay="+ignoreDeletionMarksDelay+". The args is: %v", argList)
-		}, EventuallyTimeoutMinute*1, EventuallyIntervalSecond*5).Should(Succeed())
+		}, timeout(utils.TimeoutObjectUpdate), EventuallyIntervalSecond*5).Should(Succeed())
 	})
 
 	It("[P2][Sev2][Observability][Stable] Check and tune backup retention settings in MCO CR - Check receive args (retention/g0):", func() {
@@ -112,7 +120,7 @@ var _ = Describe("Observability:", func() {
 				}
 			}
 			return fmt.Errorf("Failed to check receive args: --tsdb.retention="+retentionInLocal+". The args is: %v", argList)
-		}, EventuallyTimeoutMinute*1, EventuallyIntervalSecond*5).Should(Succeed())
+		}, timeout(utils.TimeoutObjectUpdate), EventuallyIntervalSecond*5).Should(Succeed())
 	})
 
 	It("[P2][Sev2][Observability][Stable] Check and tune backup retention settings in MCO CR - Check rule args (retention/g0):", func() {
@@ -131,7 +139,7 @@ var _ = Describe("Observability:", func() {
 				}
 			}
 			return fmt.Errorf("Failed to check rule args: --tsdb.retention="+retentionInLocal+". The args is: %v", argList)
-		}, EventuallyTimeoutMinute*1, EventuallyIntervalSecond*5).Should(Succeed())
+		}, timeout(utils.TimeoutObjectUpdate), EventuallyIntervalSecond*5).Should(Succeed())
 	})
 
 	It("[P2][Sev2][Observability][Stable] Check and tune backup retention settings in MCO CR - Check rule args (retention/g0):", func() {
@@ -150,7 +158,122 @@ var _ = Describe("Observability:", func() {
 				}
 			}
 			return fmt.Errorf("Failed to check rule args: --tsdb.block-duration="+blockDuration+". The args is: %v", argList)
-		}, EventuallyTimeoutMinute*1, EventuallyIntervalSecond*5).Should(Succeed())
+		}, timeout(utils.TimeoutObjectUpdate), EventuallyIntervalSecond*5).Should(Succeed())
+	})
+
+	It("[P2][Sev2][Observability][Integration] Should query the downsampled data past the raw retention and expire the data past the resolution retention (retention/g0)", func() {
+		blocksDir := os.Getenv("HISTORIC_BLOCKS_DIR")
+		if blocksDir == "" {
+			Skip("Skip the case since HISTORIC_BLOCKS_DIR is not set")
+		}
+		retention, err := utils.GetRetentionConfig(testOptions)
+		Expect(err).NotTo(HaveOccurred())
+		klog.V(1).Infof("Retention of thanos-compact: %+v", retention)
+
+		// the thanos components log errors while the expired blocks are deleted
+		logScanner.AllowBursts()
+		bucketClient, stopBucket, err := utils.NewMinIOBucketClient(testOptions)
+		Expect(err).NotTo(HaveOccurred())
+		defer stopBucket()
+
+		listBackfilled := func() ([]utils.BlockMeta, error) {
+			blocks, err := utils.ListBlocks(bucketClient)
+			if err != nil {
+				return nil, err
+			}
+			backfilled := []utils.BlockMeta{}
+			for _, block := range blocks {
+				if block.Thanos.Labels[backfillLabel] == "true" {
+					backfilled = append(backfilled, block)
+				}
+			}
+			return backfilled, nil
+		}
+		// the uploaded blocks and the blocks compacted or downsampled from them are deleted afterwards
+		defer func() {
+			backfilled, err := listBackfilled()
+			Expect(err).NotTo(HaveOccurred())
+			for _, block := range backfilled {
+				Expect(utils.DeleteBlock(bucketClient, block.ULID)).To(Succeed())
+			}
+		}()
+
+		By("Uploading the historic blocks in " + blocksDir)
+		uploaded, err := utils.UploadBlocks(bucketClient, blocksDir, map[string]string{backfillLabel: "true"})
+		Expect(err).NotTo(HaveOccurred())
+		minTime := time.Now()
+		for _, block := range uploaded {
+			if t := time.Unix(0, block.MinTime*int64(time.Millisecond)); t.Before(minTime) {
+				minTime = t
+			}
+		}
+
+		eventually("Waiting for thanos-compact to downsample the blocks and mark the expired blocks for deletion", func() error {
+			backfilled, err := listBackfilled()
+			if err != nil {
+				return err
+			}
+			if errs := utils.VerifyBlocks(backfilled, time.Now(), retention); len(errs) > 0 {
+				return fmt.Errorf("%v", errs)
+			}
+			return nil
+		}, timeout(utils.TimeoutCompaction), EventuallyIntervalSecond*30).Should(Succeed())
+
+		// checkResolution checks the data right after the retention of the finer resolution is only
+		// queryable with the coarser resolution
+		checkResolution := func(expiredRetention, retentionOfResolution, resolution time.Duration) {
+			if expiredRetention == 0 {
+				return
+			}
+			ts := time.Now().Add(-expiredRetention - utils.CompactionInterval - 2*time.Hour)
+			if ts.Before(minTime.Add(2*time.Hour)) || (retentionOfResolution > 0 && time.Since(ts) > retentionOfResolution) {
+				klog.V(1).Infof("Skip checking the data at %v with resolution %v, which is not covered by the blocks or the retention", ts, resolution)
+				return
+			}
+			By(fmt.Sprintf("Checking the data at %s is queryable with max_source_resolution=%v", ts.UTC().Format(time.RFC3339), resolution))
+			Eventually(func() error {
+				result, err := utils.QueryMetricAtWithResolution(testOptions, backfillQuery, ts, resolution)
+				if err != nil {
+					return err
+				}
+				if len(result.Data.Result) == 0 {
+					return fmt.Errorf("no data at %v with max_source_resolution=%v", ts, resolution)
+				}
+				return nil
+			}, timeout(utils.TimeoutMetricVisible), EventuallyIntervalSecond*30).Should(Succeed())
+		}
+		checkResolution(retention.Raw, retention.FiveMinutes, 5*time.Minute)
+		checkResolution(retention.FiveMinutes, retention.OneHour, time.Hour)
+
+		// checkExpired checks the data of the blocks out of the retention of the resolution is not queryable
+		// once thanos-store stops serving the blocks marked for deletion
+		checkExpired := func(resolution time.Duration) {
+			backfilled, err := listBackfilled()
+			Expect(err).NotTo(HaveOccurred())
+			resolutionMs := resolution.Nanoseconds() / int64(time.Millisecond)
+			times := utils.GoneDataTimes(backfilled, resolutionMs, 2*time.Hour, time.Now(), retention)
+			if len(times) == 0 {
+				klog.V(1).Infof("Skip checking the expired data with resolution %v, %d blocks are out of the retention but thanos-store still serves them within %v",
+					resolution, len(utils.ExpiredBlocks(backfilled, resolutionMs, time.Now(), retention)), retention.IgnoreDeletionMarksDelay)
+				return
+			}
+			for _, ts := range times {
+				By(fmt.Sprintf("Checking the data at %s is gone with max_source_resolution=%v", ts.UTC().Format(time.RFC3339), resolution))
+				Eventually(func() error {
+					result, err := utils.QueryMetricAtWithResolution(testOptions, backfillQuery, ts, resolution)
+					if err != nil {
+						return err
+					}
+					if len(result.Data.Result) != 0 {
+						return fmt.Errorf("the data at %v out of the retention is still queryable with max_source_resolution=%v", ts, resolution)
+					}
+					return nil
+				}, timeout(utils.TimeoutMetricGone), EventuallyIntervalSecond*30).Should(Succeed())
+			}
+		}
+		checkExpired(0)
+		checkExpired(5 * time.Minute)
+		checkExpired(time.Hour)
 	})
 
 	JustAfterEach(func() {
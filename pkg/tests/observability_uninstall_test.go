@@ -4,7 +4,6 @@
 package tests
 
 import (
-	"fmt"
 	"os"
 
 	. "github.com/onsi/ginkgo"
@@ -24,11 +23,6 @@ func uninstallMCO() {
 		testOptions.KubeConfig,
 		testOptions.HubCluster.KubeContext)
 
-	dynClient := utils.NewKubeClientDynamic(
-		testOptions.HubCluster.MasterURL,
-		testOptions.KubeConfig,
-		testOptions.HubCluster.KubeContext)
-
 	if os.Getenv("IS_CANARY_ENV") != "true" {
 		By("Deleteing the MCO testing RBAC resources")
 		Expect(utils.DeleteMCOTestingRBAC(testOptions)).NotTo(HaveOccurred())
@@ -37,50 +31,36 @@ func uninstallMCO() {
 	err := utils.UninstallMCO(testOptions)
 	Expect(err).ToNot(HaveOccurred())
 
-	By("Waiting for delete all MCO components")
-	Eventually(func() error {
-		var podList, _ = hubClient.CoreV1().Pods(MCO_NAMESPACE).List(metav1.ListOptions{})
-		if len(podList.Items) != 0 {
-			return err
-		}
-		return nil
-	}, EventuallyTimeoutMinute*5, EventuallyIntervalSecond*5).Should(Succeed())
+	await("Waiting for delete all MCO components", func() error {
+		return utils.WaitForDeleted(testOptions, true, utils.NewPodsGVR(), MCO_NAMESPACE, "",
+			timeout(utils.TimeoutUninstall))
+	})
 
-	By("Waiting for delete MCO addon instance")
-	Eventually(func() error {
-		name := MCO_CR_NAME + "-addon"
-		clientDynamic := utils.GetKubeClientDynamic(testOptions, false)
-		// should check oba instance from managedcluster
-		instance, _ := clientDynamic.Resource(utils.NewMCOAddonGVR()).Namespace(MCO_ADDON_NAMESPACE).Get(name, metav1.GetOptions{})
-		if instance != nil {
+	// should check oba instance from managedcluster
+	await("Waiting for delete MCO addon instance", func() error {
+		err := utils.WaitForDeleted(testOptions, false, utils.NewMCOAddonGVR(), MCO_ADDON_NAMESPACE, MCO_CR_NAME+"-addon",
+			timeout(utils.TimeoutUninstall))
+		if err != nil {
 			utils.PrintManagedClusterOBAObject(testOptions)
-			return fmt.Errorf("Failed to delete MCO addon instance")
 		}
-		return nil
-	}, EventuallyTimeoutMinute*5, EventuallyIntervalSecond*5).Should(Succeed())
-
-	By("Waiting for delete manifestwork")
-	Eventually(func() error {
-		name := "endpoint-observability-work"
-		_, err := dynClient.Resource(utils.NewOCMManifestworksGVR()).Namespace("local-cluster").Get(name, metav1.GetOptions{})
 		return err
-	}, EventuallyTimeoutMinute*5, EventuallyIntervalSecond*5).Should(MatchError(`manifestworks.work.open-cluster-management.io "endpoint-observability-work" not found`))
+	})
 
-	By("Waiting for delete all MCO addon components")
-	Eventually(func() error {
-		var podList, _ = hubClient.CoreV1().Pods(MCO_ADDON_NAMESPACE).List(metav1.ListOptions{})
-		if len(podList.Items) != 0 {
-			return err
-		}
-		return nil
-	}, EventuallyTimeoutMinute*5, EventuallyIntervalSecond*5).Should(Succeed())
+	await("Waiting for delete manifestwork", func() error {
+		return utils.WaitForDeleted(testOptions, true, utils.NewOCMManifestworksGVR(), "local-cluster", "endpoint-observability-work",
+			timeout(utils.TimeoutUninstall))
+	})
+
+	await("Waiting for delete all MCO addon components", func() error {
+		return utils.WaitForDeleted(testOptions, true, utils.NewPodsGVR(), MCO_ADDON_NAMESPACE, "",
+			timeout(utils.TimeoutUninstall))
+	})
 
-	By("Waiting for delete MCO namespaces")
-	Eventually(func() error {
+	eventually("Waiting for delete MCO namespaces", func() error {
 		err := hubClient.CoreV1().Namespaces().Delete(MCO_NAMESPACE, &metav1.DeleteOptions{})
 		if err != nil {
 			return err
 		}
 		return nil
-	}, EventuallyTimeoutMinute*5, EventuallyIntervalSecond*5).Should(Succeed())
+	}, timeout(utils.TimeoutUninstall), EventuallyIntervalSecond*5).Should(Succeed())
 }
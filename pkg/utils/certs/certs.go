@@ -0,0 +1,160 @@
+// Copyright (c) 2021 Red Hat, Inc.
+// Copyright Contributors to the Open Cluster Management project
+
+package certs
+
+import (
+	"bytes"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"time"
+
+	"k8s.io/klog"
+
+	"github.com/stolostron/observability-e2e-test/pkg/utils"
+)
+
+var keyUsageNames = map[x509.KeyUsage]string{
+	x509.KeyUsageDigitalSignature:  "DigitalSignature",
+	x509.KeyUsageContentCommitment: "ContentCommitment",
+	x509.KeyUsageKeyEncipherment:   "KeyEncipherment",
+	x509.KeyUsageDataEncipherment:  "DataEncipherment",
+	x509.KeyUsageKeyAgreement:      "KeyAgreement",
+	x509.KeyUsageCertSign:          "CertSign",
+	x509.KeyUsageCRLSign:           "CRLSign",
+	x509.KeyUsageEncipherOnly:      "EncipherOnly",
+	x509.KeyUsageDecipherOnly:      "DecipherOnly",
+}
+
+var extKeyUsageNames = map[x509.ExtKeyUsage]string{
+	x509.ExtKeyUsageAny:        "Any",
+	x509.ExtKeyUsageServerAuth: "ServerAuth",
+	x509.ExtKeyUsageClientAuth: "ClientAuth",
+}
+
+// CertInfo is the certificate loaded from a secret
+type CertInfo struct {
+	Secret    string
+	Namespace string
+	Key       string
+	Cert      *x509.Certificate
+	// the intermediate certificates bundled after the leaf certificate
+	Chain []*x509.Certificate
+}
+
+func (c *CertInfo) String() string {
+	return fmt.Sprintf("%s/%s[%s]: subject=%q, issuer=%q, serial=%s, notBefore=%s, notAfter=%s, SANs=%v, keyUsage=%v, extKeyUsage=%v",
+		c.Namespace, c.Secret, c.Key, c.Cert.Subject.String(), c.Cert.Issuer.String(), c.Cert.SerialNumber.String(),
+		c.Cert.NotBefore.Format(time.RFC3339), c.Cert.NotAfter.Format(time.RFC3339), c.SANs(), c.KeyUsage(), c.ExtKeyUsage())
+}
+
+// ExpiresIn returns the duration until the certificate expires
+func (c *CertInfo) ExpiresIn() time.Duration {
+	return time.Until(c.Cert.NotAfter)
+}
+
+// SANs returns the DNS names and IP addresses of the certificate
+func (c *CertInfo) SANs() []string {
+	sans := append([]string{}, c.Cert.DNSNames...)
+	for _, ip := range c.Cert.IPAddresses {
+		sans = append(sans, ip.String())
+	}
+	return sans
+}
+
+func (c *CertInfo) KeyUsage() []string {
+	usages := []string{}
+	for usage := x509.KeyUsageDigitalSignature; usage <= x509.KeyUsageDecipherOnly; usage <<= 1 {
+		if c.Cert.KeyUsage&usage != 0 {
+			usages = append(usages, keyUsageNames[usage])
+		}
+	}
+	return usages
+}
+
+func (c *CertInfo) ExtKeyUsage() []string {
+	usages := []string{}
+	for _, usage := range c.Cert.ExtKeyUsage {
+		if name, ok := extKeyUsageNames[usage]; ok {
+			usages = append(usages, name)
+		} else {
+			usages = append(usages, fmt.Sprintf("%d", usage))
+		}
+	}
+	return usages
+}
+
+// Equal checks whether both are the same certificate
+func (c *CertInfo) Equal(other *CertInfo) bool {
+	return other != nil && bytes.Equal(c.Cert.Raw, other.Cert.Raw)
+}
+
+// VerifyChain checks the certificate chains to one of the CAs for the given usage
+func (c *CertInfo) VerifyChain(usage x509.ExtKeyUsage, cas ...*CertInfo) error {
+	roots := x509.NewCertPool()
+	for _, ca := range cas {
+		roots.AddCert(ca.Cert)
+	}
+	intermediates := x509.NewCertPool()
+	for _, cert := range c.Chain {
+		intermediates.AddCert(cert)
+	}
+	_, err := c.Cert.Verify(x509.VerifyOptions{
+		Roots:         roots,
+		Intermediates: intermediates,
+		KeyUsages:     []x509.ExtKeyUsage{usage},
+	})
+	if err != nil {
+		return fmt.Errorf("certificate %s/%s[%s] does not chain to the CA: %v", c.Namespace, c.Secret, c.Key, err)
+	}
+	return nil
+}
+
+// ParseCertificates parses all certificates in the PEM data
+func ParseCertificates(data []byte) ([]*x509.Certificate, error) {
+	certs := []*x509.Certificate{}
+	for {
+		var block *pem.Block
+		block, data = pem.Decode(data)
+		if block == nil {
+			break
+		}
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		certs = append(certs, cert)
+	}
+	if len(certs) == 0 {
+		return nil, fmt.Errorf("no certificate found in PEM data")
+	}
+	return certs, nil
+}
+
+// LoadCert loads the certificate with the key of the secret
+func LoadCert(opt utils.TestOptions, isHub bool, name, namespace, key string) (*CertInfo, error) {
+	secret, err := utils.GetCertSecret(opt, isHub, name, namespace)
+	if err != nil {
+		return nil, err
+	}
+	data, ok := secret.Data[key]
+	if !ok {
+		return nil, fmt.Errorf("failed to get %s from %s secret", key, name)
+	}
+	certs, err := ParseCertificates(data)
+	if err != nil {
+		klog.Errorf("Failed to parse %s from %s secret due to %v", key, name, err)
+		return nil, err
+	}
+	return &CertInfo{
+		Secret:    name,
+		Namespace: namespace,
+		Key:       key,
+		Cert:      certs[0],
+		Chain:     certs[1:],
+	}, nil
+}
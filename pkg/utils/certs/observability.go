@@ -0,0 +1,140 @@
+// Copyright (c) 2021 Red Hat, Inc.
+// Copyright Contributors to the Open Cluster Management project
+
+package certs
+
+import (
+	"crypto/x509"
+	"fmt"
+
+	"k8s.io/klog"
+
+	"github.com/stolostron/observability-e2e-test/pkg/utils"
+)
+
+const (
+	metricsCollectorLabel = "component=metrics-collector"
+)
+
+// HubCerts are the observability certificates on the hub
+type HubCerts struct {
+	ServerCA *CertInfo
+	ClientCA *CertInfo
+	Server   *CertInfo
+	Grafana  *CertInfo
+}
+
+// ManagedClusterCerts are the observability certificates on the managed cluster
+type ManagedClusterCerts struct {
+	// the server CA distributed to the managed cluster
+	ServerCA *CertInfo
+	Client   *CertInfo
+}
+
+func LoadHubCerts(opt utils.TestOptions) (*HubCerts, error) {
+	var err error
+	certs := &HubCerts{}
+	if certs.ServerCA, err = LoadCert(opt, true, utils.ServerCACerts, utils.MCO_NAMESPACE, "tls.crt"); err != nil {
+		return nil, err
+	}
+	if certs.ClientCA, err = LoadCert(opt, true, utils.ClientCACerts, utils.MCO_NAMESPACE, "tls.crt"); err != nil {
+		return nil, err
+	}
+	if certs.Server, err = LoadCert(opt, true, utils.ServerCerts, utils.MCO_NAMESPACE, "tls.crt"); err != nil {
+		return nil, err
+	}
+	if certs.Grafana, err = LoadCert(opt, true, utils.GrafanaCerts, utils.MCO_NAMESPACE, "tls.crt"); err != nil {
+		return nil, err
+	}
+	return certs, nil
+}
+
+func LoadManagedClusterCerts(opt utils.TestOptions) (*ManagedClusterCerts, error) {
+	var err error
+	certs := &ManagedClusterCerts{}
+	if certs.ServerCA, err = LoadCert(opt, false, utils.ManagedClusterCACerts, utils.MCO_ADDON_NAMESPACE, "ca.crt"); err != nil {
+		return nil, err
+	}
+	if certs.Client, err = LoadCert(opt, false, utils.ManagedClusterClientCerts, utils.MCO_ADDON_NAMESPACE, "tls.crt"); err != nil {
+		return nil, err
+	}
+	return certs, nil
+}
+
+// Log prints the details of the hub certificates
+func (h *HubCerts) Log() {
+	for _, cert := range []*CertInfo{h.ServerCA, h.ClientCA, h.Server, h.Grafana} {
+		klog.V(1).Infof("%s, expires in %v", cert, cert.ExpiresIn())
+	}
+}
+
+// Log prints the details of the managed cluster certificates
+func (m *ManagedClusterCerts) Log() {
+	for _, cert := range []*CertInfo{m.ServerCA, m.Client} {
+		klog.V(1).Infof("%s, expires in %v", cert, cert.ExpiresIn())
+	}
+}
+
+// Verify checks the server and grafana certificates chain to the CAs on the hub
+func (h *HubCerts) Verify() error {
+	for _, ca := range []*CertInfo{h.ServerCA, h.ClientCA} {
+		if !ca.Cert.IsCA {
+			return fmt.Errorf("certificate %s/%s is not a CA", ca.Namespace, ca.Secret)
+		}
+	}
+	for _, cert := range []*CertInfo{h.ServerCA, h.ClientCA, h.Server, h.Grafana} {
+		if cert.ExpiresIn() <= 0 {
+			return fmt.Errorf("certificate %s/%s expired at %v", cert.Namespace, cert.Secret, cert.Cert.NotAfter)
+		}
+	}
+	if len(h.Server.SANs()) == 0 {
+		return fmt.Errorf("certificate %s/%s has no SANs", h.Server.Namespace, h.Server.Secret)
+	}
+	if err := h.Server.VerifyChain(x509.ExtKeyUsageServerAuth, h.ServerCA); err != nil {
+		return err
+	}
+	return h.Grafana.VerifyChain(x509.ExtKeyUsageClientAuth, h.ClientCA)
+}
+
+// VerifyManagedCluster checks the managed cluster trusts the server CA of the hub, and the client
+// certificate of the managed cluster chains to the client CA trusted by observatorium-api
+func (h *HubCerts) VerifyManagedCluster(m *ManagedClusterCerts) error {
+	if !m.ServerCA.Equal(h.ServerCA) {
+		return fmt.Errorf("server CA %s on the managed cluster is not the one on the hub", m.ServerCA.Cert.SerialNumber)
+	}
+	if m.Client.ExpiresIn() <= 0 {
+		return fmt.Errorf("certificate %s/%s expired at %v", m.Client.Namespace, m.Client.Secret, m.Client.Cert.NotAfter)
+	}
+	if err := h.Server.VerifyChain(x509.ExtKeyUsageServerAuth, m.ServerCA); err != nil {
+		return err
+	}
+	return m.Client.VerifyChain(x509.ExtKeyUsageClientAuth, h.ClientCA)
+}
+
+// CheckMetricsCollectorCert checks the running metrics-collector pods mount the client certificate secret.
+// The pod start time cannot tell the certificate a pod presents since the signers backdate NotBefore, that
+// the renewed certificate is presented is proven by the metrics received once the old one is rejected.
+func CheckMetricsCollectorCert(opt utils.TestOptions, cert *CertInfo) error {
+	err, podList := utils.GetPodList(opt, false, utils.MCO_ADDON_NAMESPACE, metricsCollectorLabel)
+	if err != nil {
+		return err
+	}
+	if len(podList.Items) == 0 {
+		return fmt.Errorf("no metrics-collector pod found")
+	}
+	for _, pod := range podList.Items {
+		if pod.DeletionTimestamp != nil {
+			continue
+		}
+		mounted := false
+		for _, volume := range pod.Spec.Volumes {
+			if volume.Secret != nil && volume.Secret.SecretName == cert.Secret {
+				mounted = true
+			}
+		}
+		if !mounted {
+			return fmt.Errorf("pod %s does not mount the secret %s", pod.Name, cert.Secret)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,208 @@
+// Copyright (c) 2021 Red Hat, Inc.
+// Copyright Contributors to the Open Cluster Management project
+
+package certs
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"fmt"
+	"math/big"
+	"net/http"
+	"regexp"
+	"time"
+
+	"k8s.io/klog"
+
+	"github.com/stolostron/observability-e2e-test/pkg/utils"
+)
+
+// the descriptions of the TLS alerts sent by a Go server when it refuses the client certificate
+const (
+	AlertBadCertificate      = "bad certificate"
+	AlertCertificateExpired  = "expired certificate"
+	AlertUnknownCA           = "unknown certificate authority"
+	AlertCertificateRequired = "certificate required"
+)
+
+var tlsAlertRegexp = regexp.MustCompile(`remote error: tls: (.+)$`)
+
+// Rejection is how observatorium-api is expected to refuse a request, by one of the TLS alerts during the
+// handshake or one of the HTTP status codes
+type Rejection struct {
+	Alerts      []string
+	StatusCodes []int
+}
+
+var (
+	// the Go servers before 1.21 send bad certificate for all the failures to verify the client certificate
+	RejectionExpiredCert = Rejection{Alerts: []string{AlertCertificateExpired, AlertBadCertificate}}
+	RejectionUnknownCA   = Rejection{Alerts: []string{AlertUnknownCA, AlertBadCertificate}}
+	// the missing client certificate is refused by certificate required in TLS 1.3 and bad certificate in
+	// TLS 1.2, or by observatorium-api with 401 if the client certificate is optional in the handshake
+	RejectionNoCert = Rejection{
+		Alerts:      []string{AlertCertificateRequired, AlertBadCertificate},
+		StatusCodes: []int{http.StatusUnauthorized},
+	}
+)
+
+// ProbeResult is the outcome of a request sent to observatorium-api
+type ProbeResult struct {
+	StatusCode int
+	// Alert is the description of the TLS alert sent by the server, e.g. bad certificate
+	Alert string
+	Err   error
+}
+
+func (r ProbeResult) String() string {
+	if r.Err != nil {
+		return fmt.Sprintf("error: %v", r.Err)
+	}
+	return fmt.Sprintf("status: %d", r.StatusCode)
+}
+
+// Accepted returns true when the request passed the TLS handshake and the authentication
+func (r ProbeResult) Accepted() bool {
+	return r.Err == nil && r.StatusCode/100 == 2
+}
+
+// RejectedAs returns true when the request was refused with one of the TLS alerts or the HTTP status
+// codes of the rejection, the other errors, e.g. a timeout, are not rejections
+func (r ProbeResult) RejectedAs(rejection Rejection) bool {
+	if r.Err != nil {
+		for _, alert := range rejection.Alerts {
+			if r.Alert == alert {
+				return true
+			}
+		}
+		return false
+	}
+	for _, code := range rejection.StatusCodes {
+		if r.StatusCode == code {
+			return true
+		}
+	}
+	return false
+}
+
+// tlsAlert returns the description of the TLS alert sent by the server in the error, empty if there is none
+func tlsAlert(err error) string {
+	if m := tlsAlertRegexp.FindStringSubmatch(err.Error()); m != nil {
+		return m[1]
+	}
+	return ""
+}
+
+// GetObservatoriumAPIRootCAs returns the CAs trusted by metrics-collector to reach observatorium-api,
+// together with the router CA of the hub if observatorium-api is exposed through the default router
+func GetObservatoriumAPIRootCAs(opt utils.TestOptions) (*x509.CertPool, error) {
+	managedCerts, err := LoadManagedClusterCerts(opt)
+	if err != nil {
+		return nil, err
+	}
+	pool := x509.NewCertPool()
+	pool.AddCert(managedCerts.ServerCA.Cert)
+	hubClient := utils.NewKubeClient(opt.HubCluster.MasterURL, opt.KubeConfig, opt.HubCluster.KubeContext)
+	if routerCA, err := utils.GetRouterCA(hubClient); err == nil {
+		pool.AppendCertsFromPEM(routerCA)
+	}
+	return pool, nil
+}
+
+// LoadKeyPair loads the certificate and private key of the secret
+func LoadKeyPair(opt utils.TestOptions, isHub bool, name, namespace string) (*tls.Certificate, error) {
+	secret, err := utils.GetCertSecret(opt, isHub, name, namespace)
+	if err != nil {
+		return nil, err
+	}
+	cert, err := tls.X509KeyPair(secret.Data["tls.crt"], secret.Data["tls.key"])
+	if err != nil {
+		return nil, fmt.Errorf("failed to load key pair from %s secret: %v", name, err)
+	}
+	cert.Leaf, err = x509.ParseCertificate(cert.Certificate[0])
+	if err != nil {
+		return nil, err
+	}
+	return &cert, nil
+}
+
+// NewClientCert issues a client certificate valid in [notBefore, notAfter]. The certificate is
+// self-signed when the CA is nil.
+func NewClientCert(commonName string, ca *tls.Certificate, notBefore, notAfter time.Time) (*tls.Certificate, error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return nil, err
+	}
+	template := &x509.Certificate{
+		SerialNumber: serial,
+		Subject:      pkix.Name{CommonName: commonName, Organization: []string{"observability-e2e-test"}},
+		NotBefore:    notBefore,
+		NotAfter:     notAfter,
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	parent := template
+	var signer crypto.Signer = key
+	if ca != nil {
+		parent = ca.Leaf
+		var ok bool
+		if signer, ok = ca.PrivateKey.(crypto.Signer); !ok {
+			return nil, fmt.Errorf("the private key of the CA is not a signer")
+		}
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, parent, key.Public(), signer)
+	if err != nil {
+		return nil, err
+	}
+	return &tls.Certificate{
+		Certificate: [][]byte{der},
+		PrivateKey:  key,
+	}, nil
+}
+
+// ProbeObservatoriumAPI sends an empty remote write request to the endpoint with the client certificate,
+// no client certificate is presented when it is nil
+func ProbeObservatoriumAPI(endpoint string, roots *x509.CertPool, clientCert *tls.Certificate) ProbeResult {
+	body, err := utils.EncodeRemoteWriteRequest(nil)
+	if err != nil {
+		return ProbeResult{Err: err}
+	}
+	req, err := http.NewRequest("POST", endpoint, bytes.NewReader(body))
+	if err != nil {
+		return ProbeResult{Err: err}
+	}
+	req.Header.Set("Content-Encoding", "snappy")
+	req.Header.Set("Content-Type", "application/x-protobuf")
+	req.Header.Set("X-Prometheus-Remote-Write-Version", "0.1.0")
+
+	tlsConfig := &tls.Config{RootCAs: roots}
+	if clientCert != nil {
+		// present the certificate even if it is not issued by the CAs the server asks for, otherwise the
+		// certificate of a foreign CA is never sent
+		tlsConfig.GetClientCertificate = func(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+			return clientCert, nil
+		}
+	}
+	client := &http.Client{
+		Transport: &http.Transport{TLSClientConfig: tlsConfig, DisableKeepAlives: true},
+		Timeout:   30 * time.Second,
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		klog.V(1).Infof("Probe to %s failed due to %v", endpoint, err)
+		return ProbeResult{Alert: tlsAlert(err), Err: err}
+	}
+	defer resp.Body.Close()
+	klog.V(1).Infof("Probe to %s returned status %d", endpoint, resp.StatusCode)
+	return ProbeResult{StatusCode: resp.StatusCode}
+}
@@ -0,0 +1,84 @@
+// Copyright (c) 2021 Red Hat, Inc.
+// Copyright Contributors to the Open Cluster Management project
+
+package certs
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestCA(t *testing.T) *tls.Certificate {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "observability-client-ca"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, key.Public(), key)
+	require.NoError(t, err)
+	leaf, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+	return &tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key, Leaf: leaf}
+}
+
+func TestProbeRejection(t *testing.T) {
+	ca := newTestCA(t)
+	clientCAs := x509.NewCertPool()
+	clientCAs.AddCert(ca.Leaf)
+
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	server.TLS = &tls.Config{ClientAuth: tls.RequireAndVerifyClientCert, ClientCAs: clientCAs}
+	server.Config.ErrorLog = log.New(ioutil.Discard, "", 0)
+	server.StartTLS()
+	defer server.Close()
+	roots := x509.NewCertPool()
+	roots.AddCert(server.Certificate())
+
+	valid, err := NewClientCert("e2e-valid", ca, time.Now().Add(-time.Hour), time.Now().Add(time.Hour))
+	require.NoError(t, err)
+	result := ProbeObservatoriumAPI(server.URL, roots, valid)
+	assert.True(t, result.Accepted(), result.String())
+	assert.False(t, result.RejectedAs(RejectionNoCert))
+
+	expired, err := NewClientCert("e2e-expired", ca, time.Now().Add(-48*time.Hour), time.Now().Add(-24*time.Hour))
+	require.NoError(t, err)
+	result = ProbeObservatoriumAPI(server.URL, roots, expired)
+	assert.True(t, result.RejectedAs(RejectionExpiredCert), result.String())
+
+	foreign, err := NewClientCert("e2e-foreign", nil, time.Now().Add(-time.Hour), time.Now().Add(time.Hour))
+	require.NoError(t, err)
+	result = ProbeObservatoriumAPI(server.URL, roots, foreign)
+	assert.True(t, result.RejectedAs(RejectionUnknownCA), result.String())
+
+	result = ProbeObservatoriumAPI(server.URL, roots, nil)
+	assert.True(t, result.RejectedAs(RejectionNoCert), result.String())
+
+	// the other errors are not rejections
+	result = ProbeResult{Err: fmt.Errorf("dial tcp: i/o timeout")}
+	assert.False(t, result.RejectedAs(RejectionNoCert))
+	assert.True(t, ProbeResult{StatusCode: http.StatusUnauthorized}.RejectedAs(RejectionNoCert))
+	assert.False(t, ProbeResult{StatusCode: http.StatusUnauthorized}.RejectedAs(RejectionUnknownCA))
+}
@@ -4,10 +4,14 @@
 package utils
 
 import (
+	"fmt"
+
 	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/kubernetes"
 )
 
+const LocalClusterName = "local-cluster"
+
 func getKubeClient(opt TestOptions, isHub bool) kubernetes.Interface {
 	clientKube := NewKubeClient(
 		opt.HubCluster.MasterURL,
@@ -53,3 +57,57 @@ func GetManagedClusterName(opt TestOptions) string {
 	}
 	return ""
 }
+
+// GetManagedClusterKubeClient returns the client of the managed cluster with the given name.
+// The hub client is returned for local-cluster since the hub manages itself.
+func GetManagedClusterKubeClient(opt TestOptions, name string) (kubernetes.Interface, error) {
+	if name == LocalClusterName {
+		return getKubeClient(opt, true), nil
+	}
+	for _, cluster := range opt.ManagedClusters {
+		if cluster.Name == name {
+			return NewKubeClient(cluster.MasterURL, cluster.KubeConfig, ""), nil
+		}
+	}
+	return nil, fmt.Errorf("managed cluster %s is not found in the options", name)
+}
+
+// GetManagedClusterKubeClientDynamic returns the dynamic client of the managed cluster with the given name.
+// The hub client is returned for local-cluster since the hub manages itself.
+func GetManagedClusterKubeClientDynamic(opt TestOptions, name string) (dynamic.Interface, error) {
+	if name == LocalClusterName {
+		return GetKubeClientDynamic(opt, true), nil
+	}
+	for _, cluster := range opt.ManagedClusters {
+		if cluster.Name == name {
+			return NewKubeClientDynamic(cluster.MasterURL, cluster.KubeConfig, ""), nil
+		}
+	}
+	return nil, fmt.Errorf("managed cluster %s is not found in the options", name)
+}
+
+// HubClusterName is the name of the hub in the clients returned by getClusterClients
+const HubClusterName = "hub"
+
+// getClusterClients returns the clients of the hub and the managed clusters in the options by cluster name
+func getClusterClients(opt TestOptions) map[string]kubernetes.Interface {
+	clients := map[string]kubernetes.Interface{
+		HubClusterName: getKubeClient(opt, true),
+	}
+	for _, cluster := range opt.ManagedClusters {
+		clients[cluster.Name] = NewKubeClient(cluster.MasterURL, cluster.KubeConfig, "")
+	}
+	return clients
+}
+
+// getClusterDynamicClients returns the dynamic clients of the hub and the managed clusters in the options
+// by cluster name
+func getClusterDynamicClients(opt TestOptions) map[string]dynamic.Interface {
+	clients := map[string]dynamic.Interface{
+		HubClusterName: GetKubeClientDynamic(opt, true),
+	}
+	for _, cluster := range opt.ManagedClusters {
+		clients[cluster.Name] = NewKubeClientDynamic(cluster.MasterURL, cluster.KubeConfig, "")
+	}
+	return clients
+}
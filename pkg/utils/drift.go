@@ -0,0 +1,195 @@
+// Copyright (c) 2021 Red Hat, Inc.
+// Copyright Contributors to the Open Cluster Management project
+
+package utils
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/tools/cache"
+)
+
+// DriftMutation is the manual change made on an object managed by the operators
+type DriftMutation string
+
+const (
+	// the object is deleted and should be recreated
+	DriftDelete DriftMutation = "delete"
+	// the JSON patch is applied on the object
+	DriftJSONPatch DriftMutation = "jsonPatch"
+	// the labels are set on the object
+	DriftLabels DriftMutation = "labels"
+)
+
+// DriftCase is a manual change of an object managed by the operators, which is expected to be reverted
+type DriftCase struct {
+	GVR schema.GroupVersionResource
+	// Namespace is empty for the cluster scoped objects
+	Namespace string
+	Name      string
+	// Hub is true for the objects on the hub, false for the ones on the managed cluster
+	Hub      bool
+	Mutation DriftMutation
+	// Patch is the JSON patch of DriftJSONPatch
+	Patch string
+	// Labels are the labels set by DriftLabels
+	Labels map[string]string
+	// Restored are the JSON pointers of the fields expected to be restored to their values before the
+	// mutation, e.g. /spec/template/spec/serviceAccountName
+	Restored []string
+	// Components are the MCO components expected to restart after the mutation, their pods are tracked
+	// before the mutation
+	Components []string
+	// Timeout is the timeout of the revert
+	Timeout TimeoutName
+	// RolloutTimeout is the timeout of the restart of the components after the revert, TimeoutRolloutRestart
+	// if it is not set
+	RolloutTimeout TimeoutName
+}
+
+func (c DriftCase) String() string {
+	name := c.Name
+	if c.Namespace != "" {
+		name = c.Namespace + "/" + name
+	}
+	return fmt.Sprintf("%s %s in %s", c.GVR.Resource, name, clusterOf(c.Hub))
+}
+
+// CheckDriftReverted makes the change of the drift case and waits for the operators to revert it, then for
+// the pods of the components to be replaced
+func CheckDriftReverted(opt TestOptions, c DriftCase) error {
+	// the pods are tracked before the mutation, a rollout check after the revert would pass before the
+	// operators even touched the workloads
+	trackers := []*RolloutTracker{}
+	for _, name := range c.Components {
+		tracker, err := NewComponentRolloutTracker(opt, name)
+		if err != nil {
+			return err
+		}
+		trackers = append(trackers, tracker)
+	}
+
+	client := GetKubeClientDynamic(opt, c.Hub).Resource(c.GVR).Namespace(c.Namespace)
+	if err := checkDriftReverted(client, c, GetTimeout(opt, c.Timeout)); err != nil {
+		return err
+	}
+	rolloutTimeout := c.RolloutTimeout
+	if rolloutTimeout == "" {
+		rolloutTimeout = TimeoutRolloutRestart
+	}
+	deadline := time.Now().Add(GetTimeout(opt, rolloutTimeout))
+	for _, tracker := range trackers {
+		if err := tracker.WaitReplaced(time.Until(deadline)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func checkDriftReverted(client dynamic.ResourceInterface, c DriftCase, timeout time.Duration) error {
+	obj, err := client.Get(c.Name, metav1.GetOptions{})
+	if err != nil {
+		return err
+	}
+	expected := map[string]interface{}{}
+	for _, pointer := range c.Restored {
+		value, found := jsonPointerValue(obj.Object, pointer)
+		if !found {
+			return fmt.Errorf("%s should have the field %s", c, pointer)
+		}
+		expected[pointer] = value
+	}
+
+	mutated, err := mutateDrift(client, c)
+	if err != nil {
+		return fmt.Errorf("failed to change %s: %v", c, err)
+	}
+	if mutated != nil && len(c.Restored) > 0 && checkRestored(mutated, c.Restored, expected) == nil {
+		return fmt.Errorf("the %s of %s should change the fields %v", c.Mutation, c, c.Restored)
+	}
+
+	lw := resourceListWatch(client, c.Name)
+	return waitForState(lw, &unstructured.Unstructured{}, c.String()+" to be reverted", timeout, func(store cache.Store) (bool, string) {
+		objs := store.List()
+		if len(objs) == 0 {
+			return false, "not found"
+		}
+		current := objs[0].(*unstructured.Unstructured)
+		if c.Mutation == DriftDelete && current.GetUID() == obj.GetUID() {
+			return false, "not deleted yet"
+		}
+		if err := checkRestored(current, c.Restored, expected); err != nil {
+			return false, err.Error()
+		}
+		return true, "reverted"
+	})
+}
+
+// mutateDrift makes the change of the drift case and returns the changed object, nil if it is deleted
+func mutateDrift(client dynamic.ResourceInterface, c DriftCase) (*unstructured.Unstructured, error) {
+	switch c.Mutation {
+	case DriftDelete:
+		return nil, client.Delete(c.Name, &metav1.DeleteOptions{})
+	case DriftJSONPatch:
+		return client.Patch(c.Name, types.JSONPatchType, []byte(c.Patch), metav1.PatchOptions{})
+	case DriftLabels:
+		patch, err := json.Marshal(map[string]interface{}{
+			"metadata": map[string]interface{}{"labels": c.Labels},
+		})
+		if err != nil {
+			return nil, err
+		}
+		return client.Patch(c.Name, types.MergePatchType, patch, metav1.PatchOptions{})
+	}
+	return nil, fmt.Errorf("unknown mutation %s", c.Mutation)
+}
+
+func checkRestored(obj *unstructured.Unstructured, pointers []string, expected map[string]interface{}) error {
+	for _, pointer := range pointers {
+		value, found := jsonPointerValue(obj.Object, pointer)
+		if !found {
+			return fmt.Errorf("%s is not restored yet", pointer)
+		}
+		if !reflect.DeepEqual(value, expected[pointer]) {
+			return fmt.Errorf("%s should be %v but got %v", pointer, expected[pointer], value)
+		}
+	}
+	return nil
+}
+
+// jsonPointerValue returns the value of the JSON pointer in the object, e.g. /subjects/0/name
+func jsonPointerValue(obj interface{}, pointer string) (interface{}, bool) {
+	if pointer == "" {
+		return obj, true
+	}
+	for _, token := range strings.Split(strings.TrimPrefix(pointer, "/"), "/") {
+		token = strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+		switch v := obj.(type) {
+		case map[string]interface{}:
+			value, ok := v[token]
+			if !ok {
+				return nil, false
+			}
+			obj = value
+		case []interface{}:
+			i, err := strconv.Atoi(token)
+			if err != nil || i < 0 || i >= len(v) {
+				return nil, false
+			}
+			obj = v[i]
+		default:
+			return nil, false
+		}
+	}
+	return obj, true
+}
@@ -0,0 +1,99 @@
+// Copyright (c) 2021 Red Hat, Inc.
+// Copyright Contributors to the Open Cluster Management project
+
+package utils
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+)
+
+func newViewCRB(uid, subject string) *unstructured.Unstructured {
+	return &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "rbac.authorization.k8s.io/v1",
+		"kind":       "ClusterRoleBinding",
+		"metadata": map[string]interface{}{
+			"name": "metrics-collector-view",
+			"uid":  uid,
+		},
+		"subjects": []interface{}{
+			map[string]interface{}{"kind": "ServiceAccount", "name": subject},
+		},
+	}}
+}
+
+func TestJSONPointerValue(t *testing.T) {
+	obj := newViewCRB("uid-1", "endpoint-observability-operator-sa").Object
+	value, found := jsonPointerValue(obj, "/subjects/0/name")
+	assert.True(t, found)
+	assert.Equal(t, "endpoint-observability-operator-sa", value)
+	_, found = jsonPointerValue(obj, "/subjects/1/name")
+	assert.False(t, found)
+	_, found = jsonPointerValue(obj, "/metadata/labels/app")
+	assert.False(t, found)
+
+	obj["metadata"].(map[string]interface{})["labels"] = map[string]interface{}{"app.kubernetes.io/name": "metrics"}
+	value, found = jsonPointerValue(obj, "/metadata/labels/app.kubernetes.io~1name")
+	assert.True(t, found)
+	assert.Equal(t, "metrics", value)
+}
+
+func TestCheckDriftReverted(t *testing.T) {
+	client := dynamicfake.NewSimpleDynamicClient(runtime.NewScheme(), newViewCRB("uid-1", "endpoint-observability-operator-sa"))
+	crbs := client.Resource(NewClusterRoleBindingsGVR())
+
+	patch := DriftCase{
+		GVR:      NewClusterRoleBindingsGVR(),
+		Name:     "metrics-collector-view",
+		Mutation: DriftJSONPatch,
+		Patch:    `[{"op": "replace", "path": "/subjects/0/name", "value": "test-subject"}]`,
+		Restored: []string{"/subjects/0/name"},
+	}
+	err := checkDriftReverted(crbs, patch, 200*time.Millisecond)
+	require.Error(t, err)
+	_, ok := err.(*WaitTimeoutError)
+	assert.True(t, ok)
+	assert.Contains(t, err.Error(), "/subjects/0/name should be endpoint-observability-operator-sa but got test-subject")
+
+	// the drift is reverted by the operator
+	revert := func() {
+		_, _ = crbs.Patch("metrics-collector-view", types.JSONPatchType,
+			[]byte(`[{"op": "replace", "path": "/subjects/0/name", "value": "endpoint-observability-operator-sa"}]`), metav1.PatchOptions{})
+	}
+	revert()
+	go func() {
+		time.Sleep(100 * time.Millisecond)
+		revert()
+	}()
+	assert.NoError(t, checkDriftReverted(crbs, DriftCase{
+		GVR:      NewClusterRoleBindingsGVR(),
+		Name:     "metrics-collector-view",
+		Mutation: DriftJSONPatch,
+		Patch:    `[{"op": "replace", "path": "/subjects/0/name", "value": "other-subject"}]`,
+		Restored: []string{"/subjects/0/name"},
+	}, 10*time.Second))
+
+	// the mutation should change the restored fields
+	patch.Patch = `[{"op": "add", "path": "/metadata/labels", "value": {"drift": "true"}}]`
+	assert.Contains(t, checkDriftReverted(crbs, patch, time.Second).Error(), "should change the fields")
+
+	// the object is recreated by the operator
+	go func() {
+		time.Sleep(100 * time.Millisecond)
+		_, _ = crbs.Create(newViewCRB("uid-2", "endpoint-observability-operator-sa"), metav1.CreateOptions{})
+	}()
+	assert.NoError(t, checkDriftReverted(crbs, DriftCase{
+		GVR:      NewClusterRoleBindingsGVR(),
+		Name:     "metrics-collector-view",
+		Mutation: DriftDelete,
+		Restored: []string{"/subjects/0/name"},
+	}, 10*time.Second))
+}
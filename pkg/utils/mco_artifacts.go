@@ -0,0 +1,335 @@
+// Copyright (c) 2021 Red Hat, Inc.
+// Copyright Contributors to the Open Cluster Management project
+
+package utils
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/klog"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	// the max lines of the logs of each container in the artifacts
+	artifactLogTailLines = int64(10000)
+	redactedValue        = "REDACTED"
+	// the max length of the names of the artifact files and directories
+	maxArtifactNameLength = 128
+)
+
+var artifactNameRegexp = regexp.MustCompile(`[^a-zA-Z0-9._-]+`)
+
+// artifactResource is a custom resource collected from the clusters, empty namespace means all namespaces
+type artifactResource struct {
+	name      string
+	gvr       schema.GroupVersionResource
+	namespace string
+	hubOnly   bool
+}
+
+var artifactResources = []artifactResource{
+	{name: "multiclusterobservabilities", gvr: NewMCOGVRV1BETA2(), hubOnly: true},
+	{name: "observatoria", gvr: NewMCOMObservatoriumGVR(), namespace: MCO_NAMESPACE, hubOnly: true},
+	{name: "managedclusteraddons", gvr: NewMCOManagedClusterAddonsGVR(), hubOnly: true},
+	{name: "manifestworks", gvr: NewOCMManifestworksGVR(), hubOnly: true},
+	{name: "placementrules", gvr: NewOCMPlacementRuleGVR(), namespace: MCO_NAMESPACE, hubOnly: true},
+	{name: "observabilityaddons", gvr: NewMCOAddonGVR()},
+}
+
+// ArtifactCollector writes the objects, workloads, configmaps, redacted secrets, pod logs, events and
+// describe-style summaries of the hub and managed clusters to a directory per spec
+type ArtifactCollector struct {
+	Dir string
+
+	clients    map[string]kubernetes.Interface
+	dynClients map[string]dynamic.Interface
+}
+
+// NewArtifactCollector returns a collector writing to the directory
+func NewArtifactCollector(opt TestOptions, dir string) *ArtifactCollector {
+	return &ArtifactCollector{
+		Dir:        dir,
+		clients:    getClusterClients(opt),
+		dynClients: getClusterDynamicClients(opt),
+	}
+}
+
+// Collect writes the artifacts of all clusters to the directory of the spec and returns the directory,
+// the failures of collecting an artifact are written to errors.txt instead of failing the collection
+func (c *ArtifactCollector) Collect(spec string) (string, error) {
+	dir := filepath.Join(c.Dir, artifactName(spec))
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", err
+	}
+	errs := []string{}
+	for cluster, client := range c.clients {
+		clusterDir := filepath.Join(dir, artifactName(cluster))
+		for _, err := range c.collectCluster(cluster, client, c.dynClients[cluster], clusterDir) {
+			errs = append(errs, fmt.Sprintf("%s: %v", cluster, err))
+		}
+	}
+	if len(errs) > 0 {
+		sort.Strings(errs)
+		if err := ioutil.WriteFile(filepath.Join(dir, "errors.txt"), []byte(strings.Join(errs, "\n")+"\n"), 0644); err != nil {
+			return dir, err
+		}
+	}
+	klog.V(1).Infof("Collected the artifacts of %q to %s", spec, dir)
+	return dir, nil
+}
+
+func (c *ArtifactCollector) collectCluster(cluster string, client kubernetes.Interface, dynClient dynamic.Interface, dir string) []error {
+	errs := []error{}
+	collect := func(err error) {
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	for _, r := range artifactResources {
+		if r.hubOnly && cluster != HubClusterName {
+			continue
+		}
+		list, err := dynClient.Resource(r.gvr).Namespace(r.namespace).List(metav1.ListOptions{})
+		if err != nil {
+			collect(fmt.Errorf("failed to list %s: %v", r.name, err))
+			continue
+		}
+		collect(writeYAML(filepath.Join(dir, "objects", r.name+".yaml"), list.Items))
+	}
+
+	for _, ns := range []string{MCO_NAMESPACE, MCO_ADDON_NAMESPACE} {
+		nsDir := filepath.Join(dir, ns)
+		deploys, err := client.AppsV1().Deployments(ns).List(metav1.ListOptions{})
+		if err == nil {
+			err = writeYAML(filepath.Join(nsDir, "deployments.yaml"), deploys.Items)
+		}
+		collect(err)
+		sts, err := client.AppsV1().StatefulSets(ns).List(metav1.ListOptions{})
+		if err == nil {
+			err = writeYAML(filepath.Join(nsDir, "statefulsets.yaml"), sts.Items)
+		}
+		collect(err)
+		cms, err := client.CoreV1().ConfigMaps(ns).List(metav1.ListOptions{})
+		if err == nil {
+			err = writeYAML(filepath.Join(nsDir, "configmaps.yaml"), cms.Items)
+		}
+		collect(err)
+		secrets, err := client.CoreV1().Secrets(ns).List(metav1.ListOptions{})
+		if err == nil {
+			err = writeYAML(filepath.Join(nsDir, "secrets.yaml"), RedactSecrets(secrets.Items))
+		}
+		collect(err)
+
+		events, err := client.CoreV1().Events(ns).List(metav1.ListOptions{})
+		if err != nil {
+			collect(err)
+			events = &corev1.EventList{}
+		}
+		sort.Slice(events.Items, func(i, j int) bool {
+			return eventTime(events.Items[i]).Before(eventTime(events.Items[j]))
+		})
+		collect(writeYAML(filepath.Join(nsDir, "events.yaml"), events.Items))
+
+		pods, err := client.CoreV1().Pods(ns).List(metav1.ListOptions{})
+		if err != nil {
+			collect(err)
+			continue
+		}
+		collect(writeYAML(filepath.Join(nsDir, "pods.yaml"), pods.Items))
+		describe := &bytes.Buffer{}
+		for _, pod := range pods.Items {
+			describePod(describe, pod, events.Items)
+			for _, status := range containerStatuses(pod) {
+				logDir := filepath.Join(nsDir, "logs", pod.Name)
+				logs, err := getPodLogs(client, ns, pod.Name, status.Name, false, artifactLogTailLines, time.Time{})
+				if err == nil {
+					err = writeFile(filepath.Join(logDir, status.Name+".log"), []byte(logs))
+				}
+				collect(err)
+				if status.RestartCount > 0 {
+					logs, err := getPodLogs(client, ns, pod.Name, status.Name, true, artifactLogTailLines, time.Time{})
+					if err == nil {
+						err = writeFile(filepath.Join(logDir, status.Name+".previous.log"), []byte(logs))
+					}
+					collect(err)
+				}
+			}
+		}
+		collect(writeFile(filepath.Join(nsDir, "describe.txt"), describe.Bytes()))
+	}
+	return errs
+}
+
+// RedactSecrets returns the copies of the secrets with the values of the data replaced
+func RedactSecrets(secrets []corev1.Secret) []corev1.Secret {
+	redacted := []corev1.Secret{}
+	for _, s := range secrets {
+		s = *s.DeepCopy()
+		for k := range s.Data {
+			s.Data[k] = []byte(redactedValue)
+		}
+		for k := range s.StringData {
+			s.StringData[k] = redactedValue
+		}
+		// the last applied configuration has the values as well
+		delete(s.Annotations, "kubectl.kubernetes.io/last-applied-configuration")
+		redacted = append(redacted, s)
+	}
+	return redacted
+}
+
+// describePod writes the describe-style summary of the pod and its events
+func describePod(w io.Writer, pod corev1.Pod, events []corev1.Event) {
+	fmt.Fprintf(w, "Name:      %s\n", pod.Name)
+	fmt.Fprintf(w, "Namespace: %s\n", pod.Namespace)
+	fmt.Fprintf(w, "Node:      %s\n", pod.Spec.NodeName)
+	fmt.Fprintf(w, "Phase:     %s\n", pod.Status.Phase)
+	if pod.Status.Reason != "" {
+		fmt.Fprintf(w, "Reason:    %s: %s\n", pod.Status.Reason, pod.Status.Message)
+	}
+	fmt.Fprintf(w, "Conditions:\n")
+	for _, cond := range pod.Status.Conditions {
+		fmt.Fprintf(w, "  %s=%s %s\n", cond.Type, cond.Status, cond.Message)
+	}
+	fmt.Fprintf(w, "Containers:\n")
+	for _, status := range containerStatuses(pod) {
+		fmt.Fprintf(w, "  %s: ready=%v restarts=%d state=%s\n", status.Name, status.Ready, status.RestartCount,
+			describeContainerState(status.State))
+		if status.LastTerminationState.Terminated != nil {
+			fmt.Fprintf(w, "    last state=%s\n", describeContainerState(status.LastTerminationState))
+		}
+	}
+	fmt.Fprintf(w, "Events:\n")
+	for _, e := range events {
+		if e.InvolvedObject.Kind == "Pod" && e.InvolvedObject.Name == pod.Name {
+			fmt.Fprintf(w, "  %s %s %s (x%d): %s\n", eventTime(e).UTC().Format(time.RFC3339), e.Type, e.Reason, e.Count, e.Message)
+		}
+	}
+	fmt.Fprintf(w, "\n")
+}
+
+func describeContainerState(state corev1.ContainerState) string {
+	switch {
+	case state.Running != nil:
+		return fmt.Sprintf("Running since %s", state.Running.StartedAt.UTC().Format(time.RFC3339))
+	case state.Waiting != nil:
+		return fmt.Sprintf("Waiting %s: %s", state.Waiting.Reason, state.Waiting.Message)
+	case state.Terminated != nil:
+		return fmt.Sprintf("Terminated %s exitCode=%d at %s", state.Terminated.Reason, state.Terminated.ExitCode,
+			state.Terminated.FinishedAt.UTC().Format(time.RFC3339))
+	}
+	return "Unknown"
+}
+
+func containerStatuses(pod corev1.Pod) []corev1.ContainerStatus {
+	statuses := append([]corev1.ContainerStatus{}, pod.Status.InitContainerStatuses...)
+	return append(statuses, pod.Status.ContainerStatuses...)
+}
+
+func eventTime(e corev1.Event) time.Time {
+	if !e.LastTimestamp.IsZero() {
+		return e.LastTimestamp.Time
+	}
+	if !e.EventTime.IsZero() {
+		return e.EventTime.Time
+	}
+	return e.CreationTimestamp.Time
+}
+
+// Archive writes the directory of the collector to a gzipped tarball
+func (c *ArtifactCollector) Archive(file string) error {
+	if _, err := os.Stat(c.Dir); os.IsNotExist(err) {
+		return nil
+	}
+	out, err := os.Create(file)
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+	gz := gzip.NewWriter(out)
+	tw := tar.NewWriter(gz)
+
+	base := filepath.Dir(c.Dir)
+	err = filepath.Walk(c.Dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		header, err := tar.FileInfoHeader(info, "")
+		if err != nil {
+			return err
+		}
+		if header.Name, err = filepath.Rel(base, path); err != nil {
+			return err
+		}
+		header.Name = filepath.ToSlash(header.Name)
+		if err := tw.WriteHeader(header); err != nil {
+			return err
+		}
+		if info.IsDir() {
+			return nil
+		}
+		f, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		_, err = io.Copy(tw, f)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	if err := tw.Close(); err != nil {
+		return err
+	}
+	if err := gz.Close(); err != nil {
+		return err
+	}
+	klog.V(1).Infof("Archived the artifacts in %s to %s", c.Dir, file)
+	return nil
+}
+
+// artifactName returns the name of the file or directory for the name, the long names are truncated with
+// a hash of the name so that the names with the same prefix don't collide
+func artifactName(name string) string {
+	sanitized := strings.Trim(artifactNameRegexp.ReplaceAllString(name, "_"), "_")
+	if len(sanitized) > maxArtifactNameLength {
+		hash := sha256.Sum256([]byte(name))
+		sanitized = fmt.Sprintf("%s_%x", sanitized[:maxArtifactNameLength-9], hash[:4])
+	}
+	return sanitized
+}
+
+func writeYAML(file string, obj interface{}) error {
+	data, err := yaml.Marshal(obj)
+	if err != nil {
+		return err
+	}
+	return writeFile(file, data)
+}
+
+func writeFile(file string, data []byte) error {
+	if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(file, data, 0644)
+}
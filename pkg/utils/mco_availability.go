@@ -0,0 +1,314 @@
+// Copyright (c) 2021 Red Hat, Inc.
+// Copyright Contributors to the Open Cluster Management project
+
+package utils
+
+import (
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	policyv1beta1 "k8s.io/api/policy/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/klog"
+)
+
+const (
+	HostnameTopologyKey = "kubernetes.io/hostname"
+	ZoneTopologyKey     = "topology.kubernetes.io/zone"
+)
+
+// GetMCOAvailabilityConfig returns spec.availabilityConfig of the MCO CR, High if it is not set
+func GetMCOAvailabilityConfig(opt TestOptions) (string, error) {
+	clientDynamic := NewKubeClientDynamic(
+		opt.HubCluster.MasterURL,
+		opt.KubeConfig,
+		opt.HubCluster.KubeContext)
+	mco, getErr := clientDynamic.Resource(NewMCOGVRV1BETA2()).Get(MCO_CR_NAME, metav1.GetOptions{})
+	if getErr != nil {
+		return "", getErr
+	}
+
+	availability, _ := mco.Object["spec"].(map[string]interface{})["availabilityConfig"].(string)
+	if availability == "" {
+		return AvailabilityHigh, nil
+	}
+	return availability, nil
+}
+
+// GetExpectedReplicas returns the expected replicas of the hub components in the availability config,
+// the replicas in spec.advanced of the MCO CR take precedence over the defaults in the registry
+func GetExpectedReplicas(opt TestOptions, availability string) (map[string]int32, error) {
+	clientDynamic := NewKubeClientDynamic(
+		opt.HubCluster.MasterURL,
+		opt.KubeConfig,
+		opt.HubCluster.KubeContext)
+	mco, getErr := clientDynamic.Resource(NewMCOGVRV1BETA2()).Get(MCO_CR_NAME, metav1.GetOptions{})
+	if getErr != nil {
+		return nil, getErr
+	}
+	advancedSpec, _ := mco.Object["spec"].(map[string]interface{})["advanced"].(map[string]interface{})
+
+	expected := map[string]int32{}
+	for _, c := range HubComponents() {
+		replicas, ok := c.ExpectedReplicas(availability)
+		if !ok {
+			continue
+		}
+		if c.ReplicasConfigurable && advancedSpec != nil {
+			config, _ := advancedSpec[c.AdvancedKey].(map[string]interface{})
+			if r, ok := config["replicas"].(int64); ok {
+				replicas = int32(r)
+			}
+		}
+		expected[c.Name] = replicas
+	}
+	return expected, nil
+}
+
+// CheckComponentsReplicas checks the hub components are scaled to the expected replicas and ready
+func CheckComponentsReplicas(opt TestOptions, expected map[string]int32) error {
+	for _, c := range HubComponents() {
+		replicas, ok := expected[c.Name]
+		if !ok {
+			continue
+		}
+		workloads, err := c.GetWorkloads(opt)
+		if err != nil {
+			return err
+		}
+		if len(workloads) == 0 {
+			return fmt.Errorf("should have %s created with label %s", c.Kind, c.Label)
+		}
+		for _, w := range workloads {
+			if w.Replicas != replicas {
+				return fmt.Errorf("%s %s should have %d replicas but got %d", w.Kind, w.Name, replicas, w.Replicas)
+			}
+			if w.ReadyReplicas != replicas {
+				return fmt.Errorf("%s %s should have %d but got %d ready replicas", w.Kind, w.Name, replicas, w.ReadyReplicas)
+			}
+		}
+	}
+	return nil
+}
+
+// CheckPodDisruptionBudgets checks every component MCO creates a PodDisruptionBudget for is protected by
+// one which still allows a disruption when it has more than one replica, and does not block the eviction
+// when it has a single replica
+func CheckPodDisruptionBudgets(opt TestOptions, expected map[string]int32) error {
+	client := getKubeClient(opt, true)
+	pdbs, err := client.PolicyV1beta1().PodDisruptionBudgets(MCO_NAMESPACE).List(metav1.ListOptions{})
+	if err != nil {
+		klog.Errorf("Failed to list poddisruptionbudgets in namespace %s due to %v", MCO_NAMESPACE, err)
+		return err
+	}
+
+	for _, c := range HubComponents() {
+		replicas, ok := expected[c.Name]
+		if !ok || !c.PodDisruptionBudget {
+			continue
+		}
+		pods, err := c.GetPods(opt)
+		if err != nil {
+			return err
+		}
+		if len(pods) == 0 {
+			return fmt.Errorf("no pod found for %s with label %s", c.Name, c.Label)
+		}
+		pdb, err := findPodDisruptionBudget(pdbs.Items, pods[0])
+		if err != nil {
+			return err
+		}
+
+		if replicas > 1 {
+			if pdb == nil {
+				return fmt.Errorf("%s has %d replicas but no poddisruptionbudget", c.Name, replicas)
+			}
+			if pdb.Status.PodDisruptionsAllowed < 1 {
+				return fmt.Errorf("poddisruptionbudget %s of %s allows no disruption", pdb.Name, c.Name)
+			}
+			continue
+		}
+		if pdb != nil && pdb.Spec.MinAvailable != nil {
+			minAvailable, err := intstr.GetValueFromIntOrPercent(pdb.Spec.MinAvailable, int(replicas), true)
+			if err != nil {
+				return err
+			}
+			if minAvailable >= int(replicas) {
+				return fmt.Errorf("poddisruptionbudget %s blocks the eviction of %s with %d replicas", pdb.Name, c.Name, replicas)
+			}
+		}
+	}
+	return nil
+}
+
+func findPodDisruptionBudget(pdbs []policyv1beta1.PodDisruptionBudget, pod corev1.Pod) (*policyv1beta1.PodDisruptionBudget, error) {
+	for i := range pdbs {
+		selector, err := metav1.LabelSelectorAsSelector(pdbs[i].Spec.Selector)
+		if err != nil {
+			return nil, err
+		}
+		if !selector.Empty() && selector.Matches(labels.Set(pod.GetLabels())) {
+			return &pdbs[i], nil
+		}
+	}
+	return nil, nil
+}
+
+// CheckComponentsAntiAffinity checks the anti-affinity of the hub components, the components with more
+// than one replica must have the terms to spread the pods across the nodes and zones
+func CheckComponentsAntiAffinity(opt TestOptions, expected map[string]int32) error {
+	for _, c := range HubComponents() {
+		replicas, ok := expected[c.Name]
+		if !ok {
+			continue
+		}
+		pods, err := c.GetPods(opt)
+		if err != nil {
+			return err
+		}
+		for _, pod := range pods {
+			if err := checkPodAntiAffinity(pod, replicas > 1); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// CheckComponentsTopologySpread checks the pods of the components with more than one replica are
+// spread across as many nodes and zones as the replicas allow. MCO only sets the preferred anti-affinity,
+// so the spread is asserted when there are enough schedulable nodes (or zones) for all replicas, or the
+// anti-affinity of the topology key is required, and only logged otherwise.
+func CheckComponentsTopologySpread(opt TestOptions, expected map[string]int32) error {
+	client := getKubeClient(opt, true)
+	nodeList, err := client.CoreV1().Nodes().List(metav1.ListOptions{})
+	if err != nil {
+		klog.Errorf("Failed to list nodes due to %v", err)
+		return err
+	}
+
+	for _, c := range HubComponents() {
+		replicas, ok := expected[c.Name]
+		if !ok || replicas < 2 {
+			continue
+		}
+		pods, err := c.GetPods(opt)
+		if err != nil {
+			return err
+		}
+		if len(pods) == 0 {
+			return fmt.Errorf("no pod found for %s with label %s", c.Name, c.Label)
+		}
+
+		nodes := map[string]corev1.Node{}
+		availableHosts, availableZones := map[string]bool{}, map[string]bool{}
+		for _, node := range nodeList.Items {
+			nodes[node.Name] = node
+			if isSchedulableFor(node, pods[0]) {
+				availableHosts[node.Name] = true
+				if zone := node.Labels[ZoneTopologyKey]; zone != "" {
+					availableZones[zone] = true
+				}
+			}
+		}
+		hosts, zones := map[string]bool{}, map[string]bool{}
+		for _, pod := range pods {
+			if pod.Spec.NodeName == "" {
+				continue
+			}
+			hosts[pod.Spec.NodeName] = true
+			if zone := nodes[pod.Spec.NodeName].Labels[ZoneTopologyKey]; zone != "" {
+				zones[zone] = true
+			}
+		}
+
+		klog.V(1).Infof("%s with %d replicas is spread across %d/%d nodes and %d/%d zones",
+			c.Name, replicas, len(hosts), len(availableHosts), len(zones), len(availableZones))
+		required := requiredAntiAffinityKeys(pods[0])
+		if expectedHosts := minInt(int(replicas), len(availableHosts)); len(hosts) < expectedHosts {
+			if required[HostnameTopologyKey] || len(availableHosts) >= int(replicas) {
+				return fmt.Errorf("%s should be spread across %d nodes but got %d", c.Name, expectedHosts, len(hosts))
+			}
+			klog.Warningf("%s is spread across %d nodes, %d nodes are preferred", c.Name, len(hosts), expectedHosts)
+		}
+		if expectedZones := minInt(int(replicas), len(availableZones)); len(zones) < expectedZones {
+			if required[ZoneTopologyKey] || len(availableZones) >= int(replicas) {
+				return fmt.Errorf("%s should be spread across %d zones but got %d", c.Name, expectedZones, len(zones))
+			}
+			klog.Warningf("%s is spread across %d zones, %d zones are preferred", c.Name, len(zones), expectedZones)
+		}
+	}
+	return nil
+}
+
+// requiredAntiAffinityKeys returns the topology keys of the required pod anti-affinity terms of the pod
+func requiredAntiAffinityKeys(pod corev1.Pod) map[string]bool {
+	keys := map[string]bool{}
+	if pod.Spec.Affinity == nil || pod.Spec.Affinity.PodAntiAffinity == nil {
+		return keys
+	}
+	for _, term := range pod.Spec.Affinity.PodAntiAffinity.RequiredDuringSchedulingIgnoredDuringExecution {
+		keys[term.TopologyKey] = true
+	}
+	return keys
+}
+
+// isSchedulableFor returns true if the pod can be scheduled to the node with respect to the
+// nodeSelector of the pod and the NoSchedule taints of the node
+func isSchedulableFor(node corev1.Node, pod corev1.Pod) bool {
+	if node.Spec.Unschedulable {
+		return false
+	}
+	for k, v := range pod.Spec.NodeSelector {
+		if node.Labels[k] != v {
+			return false
+		}
+	}
+	for _, taint := range node.Spec.Taints {
+		if taint.Effect != corev1.TaintEffectNoSchedule && taint.Effect != corev1.TaintEffectNoExecute {
+			continue
+		}
+		tolerated := false
+		for _, toleration := range pod.Spec.Tolerations {
+			if toleration.ToleratesTaint(&taint) {
+				tolerated = true
+				break
+			}
+		}
+		if !tolerated {
+			return false
+		}
+	}
+	return true
+}
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+// CheckAvailabilityConfig checks the replicas, poddisruptionbudgets, anti-affinity and topology spread
+// of the hub components match the availability config
+func CheckAvailabilityConfig(opt TestOptions, availability string) error {
+	expected, err := GetExpectedReplicas(opt, availability)
+	if err != nil {
+		return err
+	}
+	checks := []func(TestOptions, map[string]int32) error{
+		CheckComponentsReplicas,
+		CheckPodDisruptionBudgets,
+		CheckComponentsAntiAffinity,
+		CheckComponentsTopologySpread,
+	}
+	for _, check := range checks {
+		if err := check(opt, expected); err != nil {
+			klog.V(1).Infof("The components do not match availability config %s: %v", availability, err)
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,365 @@
+// Copyright (c) 2021 Red Hat, Inc.
+// Copyright Contributors to the Open Cluster Management project
+
+package utils
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ghodss/yaml"
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/credentials"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog"
+)
+
+const (
+	MinIOLabel            = "app.kubernetes.io/name=minio"
+	MinIOPort             = 9000
+	BlockMetaFile         = "meta.json"
+	BlockDeletionMarkFile = "deletion-mark.json"
+	// the resolutions of the downsampled blocks in milliseconds
+	ResolutionRaw = 0
+	Resolution5m  = 5 * 60 * 1000
+	Resolution1h  = 60 * 60 * 1000
+	// the tolerance for thanos-compact to handle the blocks
+	CompactionInterval = 2 * time.Hour
+)
+
+var (
+	ulidRegexp     = regexp.MustCompile(`^[0-9A-HJKMNP-TV-Z]{26}$`)
+	durationRegexp = regexp.MustCompile(`(\d+)(ms|y|w|d|h|m|s)`)
+	durationUnits  = map[string]time.Duration{
+		"ms": time.Millisecond,
+		"s":  time.Second,
+		"m":  time.Minute,
+		"h":  time.Hour,
+		"d":  24 * time.Hour,
+		"w":  7 * 24 * time.Hour,
+		"y":  365 * 24 * time.Hour,
+	}
+)
+
+// S3Client is the client to read and write the objects of a S3 compatible bucket
+type S3Client struct {
+	*minio.Client
+	Bucket string
+}
+
+// BlockMeta is the meta.json of a thanos block
+type BlockMeta struct {
+	ULID       string `json:"ulid"`
+	MinTime    int64  `json:"minTime"`
+	MaxTime    int64  `json:"maxTime"`
+	Version    int    `json:"version"`
+	Compaction struct {
+		Level   int      `json:"level"`
+		Sources []string `json:"sources"`
+	} `json:"compaction"`
+	Thanos struct {
+		Labels     map[string]string `json:"labels"`
+		Downsample struct {
+			Resolution int64 `json:"resolution"`
+		} `json:"downsample"`
+		Source string `json:"source"`
+	} `json:"thanos"`
+	// the block has a deletion mark
+	MarkedForDeletion bool `json:"-"`
+	// DeletionTime is the time of the deletion mark
+	DeletionTime time.Time `json:"-"`
+}
+
+// blockDeletionMark is the deletion-mark.json of a thanos block
+type blockDeletionMark struct {
+	DeletionTime int64 `json:"deletion_time"`
+}
+
+func (b BlockMeta) String() string {
+	return fmt.Sprintf("%s: [%s, %s], level=%d, resolution=%d, source=%s, markedForDeletion=%v",
+		b.ULID, time.Unix(0, b.MinTime*int64(time.Millisecond)).UTC().Format(time.RFC3339),
+		time.Unix(0, b.MaxTime*int64(time.Millisecond)).UTC().Format(time.RFC3339),
+		b.Compaction.Level, b.Thanos.Downsample.Resolution, b.Thanos.Source, b.MarkedForDeletion)
+}
+
+// Range returns the time range covered by the block
+func (b BlockMeta) Range() time.Duration {
+	return time.Duration(b.MaxTime-b.MinTime) * time.Millisecond
+}
+
+// NewS3Client creates the client for the bucket, the endpoint should have the scheme
+func NewS3Client(endpoint, bucket, region, accessKey, secretKey string, insecureSkipVerify bool) (*S3Client, error) {
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return nil, err
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil, fmt.Errorf("the endpoint %s should have the scheme http or https", endpoint)
+	}
+	if region == "" {
+		region = "us-east-1"
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = &tls.Config{InsecureSkipVerify: insecureSkipVerify}
+	client, err := minio.New(u.Host, &minio.Options{
+		Creds:     credentials.NewStaticV4(accessKey, secretKey, ""),
+		Secure:    u.Scheme == "https",
+		Region:    region,
+		Transport: transport,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &S3Client{Client: client, Bucket: bucket}, nil
+}
+
+// ListObjects lists the keys and the common prefixes under the prefix, the keys in the sub directories
+// are listed when it is recursive
+func (c *S3Client) ListObjects(prefix string, recursive bool) ([]string, []string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	keys := []string{}
+	prefixes := []string{}
+	for object := range c.Client.ListObjects(ctx, c.Bucket, minio.ListObjectsOptions{Prefix: prefix, Recursive: recursive}) {
+		if object.Err != nil {
+			return nil, nil, fmt.Errorf("failed to list the objects of bucket %s: %v", c.Bucket, object.Err)
+		}
+		if strings.HasSuffix(object.Key, "/") {
+			prefixes = append(prefixes, object.Key)
+		} else {
+			keys = append(keys, object.Key)
+		}
+	}
+	return keys, prefixes, nil
+}
+
+// GetObject returns the content of the object
+func (c *S3Client) GetObject(key string) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	object, err := c.Client.GetObject(ctx, c.Bucket, key, minio.GetObjectOptions{})
+	if err != nil {
+		return nil, err
+	}
+	defer object.Close()
+	return ioutil.ReadAll(object)
+}
+
+// PutObject uploads the content of the object
+func (c *S3Client) PutObject(key string, data []byte) error {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	_, err := c.Client.PutObject(ctx, c.Bucket, key, bytes.NewReader(data), int64(len(data)), minio.PutObjectOptions{})
+	return err
+}
+
+// DeleteObject deletes the object
+func (c *S3Client) DeleteObject(key string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	return c.Client.RemoveObject(ctx, c.Bucket, key, minio.RemoveObjectOptions{})
+}
+
+// ListBlocks returns the meta of all blocks in the bucket
+func ListBlocks(c *S3Client) ([]BlockMeta, error) {
+	_, prefixes, err := c.ListObjects("", false)
+	if err != nil {
+		return nil, err
+	}
+	blocks := []BlockMeta{}
+	for _, prefix := range prefixes {
+		ulid := strings.TrimSuffix(prefix, "/")
+		if !ulidRegexp.MatchString(ulid) {
+			continue
+		}
+		keys, _, err := c.ListObjects(prefix, true)
+		if err != nil {
+			return nil, err
+		}
+		hasMeta := false
+		block := BlockMeta{}
+		for _, key := range keys {
+			switch strings.TrimPrefix(key, prefix) {
+			case BlockMetaFile:
+				hasMeta = true
+			case BlockDeletionMarkFile:
+				block.MarkedForDeletion = true
+			}
+		}
+		if !hasMeta {
+			// the block is being uploaded or deleted
+			continue
+		}
+		data, err := c.GetObject(prefix + BlockMetaFile)
+		if err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(data, &block); err != nil {
+			return nil, fmt.Errorf("failed to parse %s%s: %v", prefix, BlockMetaFile, err)
+		}
+		if block.MarkedForDeletion {
+			data, err := c.GetObject(prefix + BlockDeletionMarkFile)
+			if err != nil {
+				return nil, err
+			}
+			mark := blockDeletionMark{}
+			if err := json.Unmarshal(data, &mark); err != nil {
+				return nil, fmt.Errorf("failed to parse %s%s: %v", prefix, BlockDeletionMarkFile, err)
+			}
+			block.DeletionTime = time.Unix(mark.DeletionTime, 0)
+		}
+		blocks = append(blocks, block)
+	}
+	sort.Slice(blocks, func(i, j int) bool { return blocks[i].MinTime < blocks[j].MinTime })
+	return blocks, nil
+}
+
+// DeleteBlock deletes all objects of the block. The meta.json is deleted first so that the thanos
+// components do not load the partially deleted block.
+func DeleteBlock(c *S3Client, ulid string) error {
+	prefix := ulid + "/"
+	keys, _, err := c.ListObjects(prefix, true)
+	if err != nil {
+		return err
+	}
+	if err := c.DeleteObject(prefix + BlockMetaFile); err != nil {
+		return err
+	}
+	for _, key := range keys {
+		if key == prefix+BlockMetaFile {
+			continue
+		}
+		if err := c.DeleteObject(key); err != nil {
+			return err
+		}
+	}
+	klog.V(1).Infof("Deleted block %s", ulid)
+	return nil
+}
+
+// NewMinIOBucketClient creates the client for the bucket in the object storage secret. The in-cluster MinIO
+// is reached by forwarding its port unless the OBJECT_STORAGE_ENDPOINT env is set. The returned function
+// should be called to close the client.
+func NewMinIOBucketClient(opt TestOptions) (*S3Client, func(), error) {
+	secret, err := getObjSecretConfig(opt)
+	if err != nil {
+		return nil, nil, err
+	}
+	if secret.Type != "s3" {
+		return nil, nil, fmt.Errorf("unsupported object storage type %s", secret.Type)
+	}
+	s3 := secret.Config
+
+	endpoint := os.Getenv("OBJECT_STORAGE_ENDPOINT")
+	stop := func() {}
+	if endpoint == "" {
+		err, podList := GetPodList(opt, true, MCO_NAMESPACE, MinIOLabel)
+		if err != nil {
+			return nil, nil, err
+		}
+		if len(podList.Items) == 0 {
+			return nil, nil, fmt.Errorf("no minio pod found in namespace %s", MCO_NAMESPACE)
+		}
+		localPort, stopForward, err := PortForward(opt, MCO_NAMESPACE, podList.Items[0].Name, MinIOPort)
+		if err != nil {
+			return nil, nil, err
+		}
+		endpoint = "http://127.0.0.1:" + strconv.Itoa(localPort)
+		stop = stopForward
+	}
+	client, err := NewS3Client(endpoint, s3.Bucket, s3.Region, s3.AccessKey, s3.SecretKey, true)
+	if err != nil {
+		stop()
+		return nil, nil, err
+	}
+	return client, stop, nil
+}
+
+// objSecretConfig is the thanos object storage configuration in the object storage secret
+type objSecretConfig struct {
+	Type   string   `json:"type"`
+	Config s3Config `json:"config"`
+}
+
+func getObjSecretConfig(opt TestOptions) (*objSecretConfig, error) {
+	clientKube := getKubeClient(opt, true)
+	secret, err := clientKube.CoreV1().Secrets(MCO_NAMESPACE).Get(OBJ_SECRET_NAME, metav1.GetOptions{})
+	if err != nil {
+		klog.Errorf("Failed to get object storage secret %s due to %v", OBJ_SECRET_NAME, err)
+		return nil, err
+	}
+	config := &objSecretConfig{}
+	if err := yaml.Unmarshal(secret.Data[OBJ_SECRET_KEY], config); err != nil {
+		return nil, fmt.Errorf("failed to parse %s in secret %s: %v", OBJ_SECRET_KEY, OBJ_SECRET_NAME, err)
+	}
+	config.Type = strings.ToLower(config.Type)
+	return config, nil
+}
+
+// ParsePromDuration parses the duration in prometheus format, e.g. 5d or 1d12h
+func ParsePromDuration(s string) (time.Duration, error) {
+	matches := durationRegexp.FindAllStringSubmatch(s, -1)
+	if len(matches) == 0 || durationRegexp.ReplaceAllString(s, "") != "" {
+		return 0, fmt.Errorf("invalid duration %q", s)
+	}
+	var d time.Duration
+	for _, m := range matches {
+		n, err := strconv.ParseInt(m[1], 10, 64)
+		if err != nil {
+			return 0, err
+		}
+		d += time.Duration(n) * durationUnits[m[2]]
+	}
+	return d, nil
+}
+
+// VerifyBlocks checks the blocks are compacted and downsampled once they are old enough, and the
+// blocks out of the retention of their resolution are marked for deletion
+func VerifyBlocks(blocks []BlockMeta, now time.Time, retention RetentionConfig) []error {
+	errs := []error{}
+	nowMs := now.UnixNano() / int64(time.Millisecond)
+	var minTime int64 = nowMs
+	compacted := false
+	downsampled := map[string]bool{}
+	for _, b := range blocks {
+		if b.MinTime < minTime {
+			minTime = b.MinTime
+		}
+		if b.Compaction.Level > 1 {
+			compacted = true
+		}
+		if b.Thanos.Downsample.Resolution == Resolution5m {
+			downsampled[fmt.Sprintf("%d-%d", b.MinTime, b.MaxTime)] = true
+		}
+	}
+
+	// the blocks of the first 2h ranges are compacted into 8h blocks
+	if len(blocks) > 0 && time.Duration(nowMs-minTime)*time.Millisecond > 10*time.Hour && !compacted {
+		errs = append(errs, fmt.Errorf("no compacted block is found in %d blocks since %s", len(blocks),
+			time.Unix(0, minTime*int64(time.Millisecond)).UTC().Format(time.RFC3339)))
+	}
+	for _, b := range blocks {
+		// thanos-compact downsamples the raw blocks with the range over 40h into 5m resolution
+		if b.Thanos.Downsample.Resolution == ResolutionRaw && !b.MarkedForDeletion && b.Range() > 40*time.Hour &&
+			!downsampled[fmt.Sprintf("%d-%d", b.MinTime, b.MaxTime)] {
+			errs = append(errs, fmt.Errorf("raw block %s is not downsampled", b))
+		}
+		if r := retention.For(b.Thanos.Downsample.Resolution); !b.MarkedForDeletion && r > 0 &&
+			time.Duration(nowMs-b.MaxTime)*time.Millisecond > r+CompactionInterval {
+			errs = append(errs, fmt.Errorf("block %s is out of the retention %v but not marked for deletion", b, r))
+		}
+	}
+	return errs
+}
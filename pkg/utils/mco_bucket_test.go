@@ -0,0 +1,114 @@
+// Copyright (c) 2021 Red Hat, Inc.
+// Copyright Contributors to the Open Cluster Management project
+
+package utils
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewS3Client(t *testing.T) {
+	c, err := NewS3Client("http://127.0.0.1:9000", "thanos", "", "id", "secret", true)
+	require.NoError(t, err)
+	assert.Equal(t, "http://127.0.0.1:9000", c.EndpointURL().String())
+	assert.Equal(t, "thanos", c.Bucket)
+
+	c, err = NewS3Client("https://s3.us-east-1.amazonaws.com/", "thanos", "us-east-1", "id", "secret", false)
+	require.NoError(t, err)
+	assert.Equal(t, "https://s3.us-east-1.amazonaws.com", c.EndpointURL().String())
+
+	_, err = NewS3Client("127.0.0.1:9000", "thanos", "", "id", "secret", true)
+	assert.Error(t, err)
+}
+
+func TestParsePromDuration(t *testing.T) {
+	d, err := ParsePromDuration("1d12h")
+	require.NoError(t, err)
+	assert.Equal(t, 36*time.Hour, d)
+	_, err = ParsePromDuration("5x")
+	assert.Error(t, err)
+}
+
+func TestVerifyBlocks(t *testing.T) {
+	now := time.Date(2021, 10, 1, 0, 0, 0, 0, time.UTC)
+	ms := func(d time.Duration) int64 { return now.Add(-d).UnixNano() / int64(time.Millisecond) }
+	block := func(ulid string, from, to time.Duration, level int, resolution int64, marked bool) BlockMeta {
+		b := BlockMeta{ULID: ulid, MinTime: ms(from), MaxTime: ms(to), MarkedForDeletion: marked}
+		b.Compaction.Level = level
+		b.Thanos.Downsample.Resolution = resolution
+		return b
+	}
+
+	blocks := []BlockMeta{
+		block("A", 80*time.Hour, 30*time.Hour, 3, ResolutionRaw, false),
+		block("B", 80*time.Hour, 30*time.Hour, 3, Resolution5m, false),
+		block("C", 2*time.Hour, 0, 1, ResolutionRaw, false),
+	}
+	assert.Empty(t, VerifyBlocks(blocks, now, RetentionConfig{Raw: 30 * 24 * time.Hour}))
+
+	// the raw block out of the retention must be marked for deletion
+	errs := VerifyBlocks(blocks, now, RetentionConfig{Raw: 24 * time.Hour})
+	require.Len(t, errs, 1)
+	assert.True(t, strings.Contains(errs[0].Error(), "not marked for deletion"))
+	blocks[0].MarkedForDeletion = true
+	assert.Empty(t, VerifyBlocks(blocks, now, RetentionConfig{Raw: 24 * time.Hour}))
+
+	// the 5m block out of the retention of the 5m resolution must be marked for deletion
+	errs = VerifyBlocks(blocks, now, RetentionConfig{Raw: 24 * time.Hour, FiveMinutes: 24 * time.Hour})
+	require.Len(t, errs, 1)
+	assert.True(t, strings.Contains(errs[0].Error(), "not marked for deletion"))
+
+	// the 5m block is missing for the raw block over 40h
+	errs = VerifyBlocks(blocks[2:], now, RetentionConfig{})
+	assert.Empty(t, errs)
+	errs = VerifyBlocks([]BlockMeta{block("A", 80*time.Hour, 30*time.Hour, 3, ResolutionRaw, false)}, now, RetentionConfig{})
+	require.Len(t, errs, 1)
+	assert.True(t, strings.Contains(errs[0].Error(), "not downsampled"))
+
+	// the level 1 blocks over 10h should be compacted
+	errs = VerifyBlocks([]BlockMeta{
+		block("D", 12*time.Hour, 10*time.Hour, 1, ResolutionRaw, false),
+		block("E", 2*time.Hour, 0, 1, ResolutionRaw, false),
+	}, now, RetentionConfig{})
+	require.Len(t, errs, 1)
+	assert.True(t, strings.Contains(errs[0].Error(), "no compacted block"))
+}
+
+func TestGoneDataTimes(t *testing.T) {
+	now := time.Date(2021, 10, 1, 0, 0, 0, 0, time.UTC)
+	ms := func(d time.Duration) int64 { return now.Add(-d).UnixNano() / int64(time.Millisecond) }
+	block := func(from, to time.Duration, resolution int64, markedAgo time.Duration) BlockMeta {
+		b := BlockMeta{MinTime: ms(from), MaxTime: ms(to)}
+		b.Thanos.Downsample.Resolution = resolution
+		if markedAgo > 0 {
+			b.MarkedForDeletion = true
+			b.DeletionTime = now.Add(-markedAgo)
+		}
+		return b
+	}
+	retention := RetentionConfig{Raw: 24 * time.Hour, FiveMinutes: 48 * time.Hour, IgnoreDeletionMarksDelay: 24 * time.Hour}
+
+	blocks := []BlockMeta{
+		block(100*time.Hour, 60*time.Hour, ResolutionRaw, 30*time.Hour),
+		block(100*time.Hour, 60*time.Hour, Resolution5m, 0),
+		block(10*time.Hour, 0, ResolutionRaw, 0),
+	}
+	assert.Len(t, ExpiredBlocks(blocks, ResolutionRaw, now, retention), 1)
+	assert.Len(t, ExpiredBlocks(blocks, Resolution5m, now, retention), 1)
+	assert.Empty(t, ExpiredBlocks(blocks, Resolution1h, now, retention))
+
+	// the raw data is gone but the 5m block still serves the data
+	assert.Equal(t, []time.Time{msToTime(ms(80 * time.Hour))}, GoneDataTimes(blocks, ResolutionRaw, 2*time.Hour, now, retention))
+	assert.Empty(t, GoneDataTimes(blocks, Resolution5m, 2*time.Hour, now, retention))
+
+	// thanos-store serves the blocks marked for deletion within the delay
+	blocks[1].MarkedForDeletion, blocks[1].DeletionTime = true, now.Add(-time.Hour)
+	assert.Empty(t, GoneDataTimes(blocks, Resolution5m, 2*time.Hour, now, retention))
+	blocks[1].DeletionTime = now.Add(-25 * time.Hour)
+	assert.Equal(t, []time.Time{msToTime(ms(80 * time.Hour))}, GoneDataTimes(blocks, Resolution5m, 2*time.Hour, now, retention))
+}
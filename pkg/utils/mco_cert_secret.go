@@ -4,6 +4,7 @@
 package utils
 
 import (
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/klog"
 )
@@ -15,6 +16,15 @@ const (
 	GrafanaCerts  = "observability-grafana-certs"
 )
 
+func GetCertSecret(opt TestOptions, isHub bool, name string, namespace string) (*corev1.Secret, error) {
+	clientKube := getKubeClient(opt, isHub)
+	secret, err := clientKube.CoreV1().Secrets(namespace).Get(name, metav1.GetOptions{})
+	if err != nil {
+		klog.Errorf("Failed to get certificate secret %s in namespace %s due to %v", name, namespace, err)
+	}
+	return secret, err
+}
+
 func DeleteCertSecret(opt TestOptions) error {
 	clientKube := NewKubeClient(
 		opt.HubCluster.MasterURL,
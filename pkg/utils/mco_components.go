@@ -0,0 +1,433 @@
+// Copyright (c) 2021 Red Hat, Inc.
+// Copyright Contributors to the Open Cluster Management project
+
+package utils
+
+import (
+	"fmt"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/klog"
+)
+
+const (
+	KindDeployment  = "Deployment"
+	KindStatefulSet = "Statefulset"
+
+	AvailabilityHigh  = "High"
+	AvailabilityBasic = "Basic"
+
+	AlertmanagerLabel                 = "app=multicluster-observability-alertmanager"
+	GrafanaLabel                      = "app=multicluster-observability-grafana"
+	ObservatoriumAPILabel             = "app.kubernetes.io/name=observatorium-api"
+	ObservatoriumOperatorLabel        = "app.kubernetes.io/name=observatorium-operator"
+	RBACQueryProxyLabel               = "app=rbac-query-proxy"
+	ThanosCompactLabel                = "app.kubernetes.io/name=thanos-compact"
+	ThanosStoreLabel                  = "app.kubernetes.io/name=thanos-store"
+	ThanosReceiveLabel                = "app.kubernetes.io/name=thanos-receive"
+	ThanosReceiveControllerLabel      = "app.kubernetes.io/name=thanos-receive-controller"
+	ThanosRuleLabel                   = "app.kubernetes.io/name=thanos-rule"
+	ThanosQueryLabel                  = "app.kubernetes.io/name=thanos-query"
+	ThanosQueryFrontendLabel          = "app.kubernetes.io/name=thanos-query-frontend"
+	ThanosQueryFrontendMemcachedLabel = "app.kubernetes.io/component=query-frontend-cache,app.kubernetes.io/name=memcached"
+	ThanosStoreMemcachedLabel         = "app.kubernetes.io/component=store-cache,app.kubernetes.io/name=memcached"
+	EndpointOperatorLabel             = "name=endpoint-observability-operator"
+	MetricsCollectorLabel             = "component=metrics-collector"
+)
+
+// Component describes a workload managed by MCO
+type Component struct {
+	Name  string
+	Kind  string
+	Label string
+	// Hub is true for the components running on the hub, false for the ones running on the managed clusters
+	Hub bool
+	// AdvancedKey is the key of the component in spec.advanced of the MCO CR, empty if it is not configurable
+	AdvancedKey string
+	// ReplicasConfigurable is true if spec.advanced.<AdvancedKey>.replicas is honored
+	ReplicasConfigurable bool
+	// StorageSizeKey is the key of the storage size in spec.storageConfig of the MCO CR, empty if it has no PVC
+	StorageSizeKey string
+	// Replicas is the default replicas in each availability config, the component is not deployed if absent
+	Replicas map[string]int32
+	// PodDisruptionBudget is true if MCO creates a PodDisruptionBudget for the component
+	PodDisruptionBudget bool
+}
+
+// Workload is the deployment or statefulset of a component
+type Workload struct {
+	Name                 string
+	Kind                 string
+	Replicas             int32
+	ReadyReplicas        int32
+	UpdatedReplicas      int32
+	Generation           int64
+	ObservedGeneration   int64
+	CurrentRevision      string
+	UpdateRevision       string
+	Template             corev1.PodTemplateSpec
+	VolumeClaimTemplates []corev1.PersistentVolumeClaim
+}
+
+// CheckRolledOut checks the controller observed the latest spec of the workload and all replicas are updated
+// and ready
+func (w Workload) CheckRolledOut() error {
+	if w.ObservedGeneration < w.Generation {
+		return fmt.Errorf("%s %s should have observed generation %d but got %d", w.Kind, w.Name, w.Generation,
+			w.ObservedGeneration)
+	}
+	if w.UpdatedReplicas != w.Replicas || w.ReadyReplicas != w.Replicas {
+		return fmt.Errorf("%s %s should have %d but got %d updated and %d ready replicas", w.Kind, w.Name,
+			w.Replicas, w.UpdatedReplicas, w.ReadyReplicas)
+	}
+	if w.UpdateRevision != w.CurrentRevision {
+		return fmt.Errorf("%s %s should have revision %s but got %s", w.Kind, w.Name, w.UpdateRevision,
+			w.CurrentRevision)
+	}
+	return nil
+}
+
+func replicas(high, basic int32) map[string]int32 {
+	return map[string]int32{AvailabilityHigh: high, AvailabilityBasic: basic}
+}
+
+// MCOComponents is the registry of all the workloads managed by MCO
+var MCOComponents = []Component{
+	{
+		Name:                 "alertmanager",
+		Kind:                 KindStatefulSet,
+		Label:                AlertmanagerLabel,
+		Hub:                  true,
+		AdvancedKey:          "alertmanager",
+		ReplicasConfigurable: true,
+		StorageSizeKey:       "alertmanagerStorageSize",
+		Replicas:             replicas(3, 1),
+		PodDisruptionBudget:  true,
+	},
+	{
+		Name:                 "grafana",
+		Kind:                 KindDeployment,
+		Label:                GrafanaLabel,
+		Hub:                  true,
+		AdvancedKey:          "grafana",
+		ReplicasConfigurable: true,
+		Replicas:             replicas(2, 1),
+		PodDisruptionBudget:  true,
+	},
+	{
+		Name:                 "observatorium-api",
+		Kind:                 KindDeployment,
+		Label:                ObservatoriumAPILabel,
+		Hub:                  true,
+		AdvancedKey:          "observatoriumAPI",
+		ReplicasConfigurable: true,
+		Replicas:             replicas(2, 1),
+		PodDisruptionBudget:  true,
+	},
+	{
+		Name:     "observatorium-operator",
+		Kind:     KindDeployment,
+		Label:    ObservatoriumOperatorLabel,
+		Hub:      true,
+		Replicas: replicas(1, 1),
+	},
+	{
+		Name:                 "rbac-query-proxy",
+		Kind:                 KindDeployment,
+		Label:                RBACQueryProxyLabel,
+		Hub:                  true,
+		AdvancedKey:          "rbacQueryProxy",
+		ReplicasConfigurable: true,
+		Replicas:             replicas(2, 1),
+		PodDisruptionBudget:  true,
+	},
+	{
+		Name:           "thanos-compact",
+		Kind:           KindStatefulSet,
+		Label:          ThanosCompactLabel,
+		Hub:            true,
+		AdvancedKey:    "compact",
+		StorageSizeKey: "compactStorageSize",
+		Replicas:       replicas(1, 1),
+	},
+	{
+		Name:                 "thanos-query",
+		Kind:                 KindDeployment,
+		Label:                ThanosQueryLabel,
+		Hub:                  true,
+		AdvancedKey:          "query",
+		ReplicasConfigurable: true,
+		Replicas:             replicas(2, 1),
+		PodDisruptionBudget:  true,
+	},
+	{
+		Name:                 "thanos-query-frontend",
+		Kind:                 KindDeployment,
+		Label:                ThanosQueryFrontendLabel,
+		Hub:                  true,
+		AdvancedKey:          "queryFrontend",
+		ReplicasConfigurable: true,
+		Replicas:             replicas(2, 1),
+		PodDisruptionBudget:  true,
+	},
+	{
+		Name:                 "thanos-query-frontend-memcached",
+		Kind:                 KindStatefulSet,
+		Label:                ThanosQueryFrontendMemcachedLabel,
+		Hub:                  true,
+		AdvancedKey:          "queryFrontendMemcached",
+		ReplicasConfigurable: true,
+		Replicas:             replicas(3, 1),
+	},
+	{
+		Name:                 "thanos-receive",
+		Kind:                 KindStatefulSet,
+		Label:                ThanosReceiveLabel,
+		Hub:                  true,
+		AdvancedKey:          "receive",
+		ReplicasConfigurable: true,
+		StorageSizeKey:       "receiveStorageSize",
+		Replicas:             replicas(3, 1),
+		PodDisruptionBudget:  true,
+	},
+	{
+		Name:     "thanos-receive-controller",
+		Kind:     KindDeployment,
+		Label:    ThanosReceiveControllerLabel,
+		Hub:      true,
+		Replicas: replicas(1, 1),
+	},
+	{
+		Name:                 "thanos-rule",
+		Kind:                 KindStatefulSet,
+		Label:                ThanosRuleLabel,
+		Hub:                  true,
+		AdvancedKey:          "rule",
+		ReplicasConfigurable: true,
+		StorageSizeKey:       "ruleStorageSize",
+		Replicas:             replicas(3, 1),
+		PodDisruptionBudget:  true,
+	},
+	{
+		Name:           "thanos-store",
+		Kind:           KindStatefulSet,
+		Label:          ThanosStoreLabel,
+		Hub:            true,
+		AdvancedKey:    "store",
+		StorageSizeKey: "storeStorageSize",
+		Replicas:       replicas(1, 1),
+	},
+	{
+		Name:                 "thanos-store-memcached",
+		Kind:                 KindStatefulSet,
+		Label:                ThanosStoreMemcachedLabel,
+		Hub:                  true,
+		AdvancedKey:          "storeMemcached",
+		ReplicasConfigurable: true,
+		Replicas:             replicas(3, 1),
+	},
+	{
+		Name:     "endpoint-observability-operator",
+		Kind:     KindDeployment,
+		Label:    EndpointOperatorLabel,
+		Replicas: replicas(1, 1),
+	},
+	{
+		Name:     "metrics-collector",
+		Kind:     KindDeployment,
+		Label:    MetricsCollectorLabel,
+		Replicas: replicas(1, 1),
+	},
+}
+
+// HubComponents returns the components running on the hub
+func HubComponents() []Component {
+	components := []Component{}
+	for _, c := range MCOComponents {
+		if c.Hub {
+			components = append(components, c)
+		}
+	}
+	return components
+}
+
+// SpokeComponents returns the components running on the managed clusters
+func SpokeComponents() []Component {
+	components := []Component{}
+	for _, c := range MCOComponents {
+		if !c.Hub {
+			components = append(components, c)
+		}
+	}
+	return components
+}
+
+// GetComponent returns the component with the name in the registry
+func GetComponent(name string) (Component, error) {
+	for _, c := range MCOComponents {
+		if c.Name == name {
+			return c, nil
+		}
+	}
+	return Component{}, fmt.Errorf("component %s is not registered", name)
+}
+
+// Namespace returns the namespace where the component is deployed
+func (c Component) Namespace() string {
+	if c.Hub {
+		return MCO_NAMESPACE
+	}
+	return MCO_ADDON_NAMESPACE
+}
+
+// ExpectedReplicas returns the default replicas in the availability config, false if the component is
+// not deployed in that config
+func (c Component) ExpectedReplicas(availability string) (int32, bool) {
+	r, ok := c.Replicas[availability]
+	return r, ok && r > 0
+}
+
+// Matches returns true if the pod belongs to the component
+func (c Component) Matches(pod corev1.Pod) bool {
+	selector, err := labels.Parse(c.Label)
+	if err != nil {
+		return false
+	}
+	return selector.Matches(labels.Set(pod.GetLabels()))
+}
+
+// GetWorkloads returns the deployments or statefulsets of the component
+func (c Component) GetWorkloads(opt TestOptions) ([]Workload, error) {
+	workloads := []Workload{}
+	if c.Kind == KindDeployment {
+		deploys, err := GetDeploymentWithLabel(opt, c.Hub, c.Label, c.Namespace())
+		if err != nil {
+			return nil, err
+		}
+		for _, d := range deploys.Items {
+			workloads = append(workloads, deploymentWorkload(d))
+		}
+		return workloads, nil
+	}
+
+	sts, err := GetStatefulSetWithLabel(opt, c.Hub, c.Label, c.Namespace())
+	if err != nil {
+		return nil, err
+	}
+	for _, s := range sts.Items {
+		workloads = append(workloads, statefulSetWorkload(s))
+	}
+	return workloads, nil
+}
+
+func deploymentWorkload(d appsv1.Deployment) Workload {
+	return Workload{
+		Name:               d.Name,
+		Kind:               KindDeployment,
+		Replicas:           *d.Spec.Replicas,
+		ReadyReplicas:      d.Status.ReadyReplicas,
+		UpdatedReplicas:    d.Status.UpdatedReplicas,
+		Generation:         d.Generation,
+		ObservedGeneration: d.Status.ObservedGeneration,
+		Template:           d.Spec.Template,
+	}
+}
+
+func statefulSetWorkload(s appsv1.StatefulSet) Workload {
+	return Workload{
+		Name:                 s.Name,
+		Kind:                 KindStatefulSet,
+		Replicas:             *s.Spec.Replicas,
+		ReadyReplicas:        s.Status.ReadyReplicas,
+		UpdatedReplicas:      s.Status.UpdatedReplicas,
+		Generation:           s.Generation,
+		ObservedGeneration:   s.Status.ObservedGeneration,
+		CurrentRevision:      s.Status.CurrentRevision,
+		UpdateRevision:       s.Status.UpdateRevision,
+		Template:             s.Spec.Template,
+		VolumeClaimTemplates: s.Spec.VolumeClaimTemplates,
+	}
+}
+
+// GetPods returns the pods of the component
+func (c Component) GetPods(opt TestOptions) ([]corev1.Pod, error) {
+	err, podList := GetPodList(opt, c.Hub, c.Namespace(), c.Label)
+	if err != nil {
+		return nil, err
+	}
+	return podList.Items, nil
+}
+
+// CheckReady checks the workloads of the component are created and all replicas are ready
+func (c Component) CheckReady(opt TestOptions) error {
+	workloads, err := c.GetWorkloads(opt)
+	if err != nil {
+		return err
+	}
+	if len(workloads) == 0 {
+		return fmt.Errorf("should have %s created with label %s", c.Kind, c.Label)
+	}
+	for _, w := range workloads {
+		if w.ReadyReplicas != w.Replicas {
+			return fmt.Errorf("%s %s should have %d but got %d ready replicas",
+				w.Kind, w.Name, w.Replicas, w.ReadyReplicas)
+		}
+	}
+	return nil
+}
+
+// CheckRolledOut checks the workloads of the component are created and rolled out
+func (c Component) CheckRolledOut(opt TestOptions) error {
+	workloads, err := c.GetWorkloads(opt)
+	if err != nil {
+		return err
+	}
+	if len(workloads) == 0 {
+		return fmt.Errorf("should have %s created with label %s", c.Kind, c.Label)
+	}
+	for _, w := range workloads {
+		if err := w.CheckRolledOut(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// CheckStorageSize checks the volume claim templates of the component request the expected capacity
+func (c Component) CheckStorageSize(opt TestOptions, expectedCapacity string) error {
+	if c.StorageSizeKey == "" {
+		return fmt.Errorf("component %s has no storage", c.Name)
+	}
+	workloads, err := c.GetWorkloads(opt)
+	if err != nil {
+		return err
+	}
+	if len(workloads) == 0 {
+		return fmt.Errorf("should have %s created with label %s", c.Kind, c.Label)
+	}
+	for _, w := range workloads {
+		if len(w.VolumeClaimTemplates) == 0 {
+			return fmt.Errorf("%s %s has no volume claim template", w.Kind, w.Name)
+		}
+		capacity := w.VolumeClaimTemplates[0].Spec.Resources.Requests[corev1.ResourceStorage]
+		if !capacity.Equal(resource.MustParse(expectedCapacity)) {
+			klog.V(1).Infof("The storage size of %s %s is %v", w.Kind, w.Name, capacity.String())
+			return fmt.Errorf("the storage size of %s %s should have %s but got %v",
+				w.Kind, w.Name, expectedCapacity, capacity.String())
+		}
+	}
+	return nil
+}
+
+// IsMCOPod returns true if the pod belongs to a hub component in the registry
+func IsMCOPod(pod corev1.Pod) bool {
+	for _, c := range HubComponents() {
+		if c.Matches(pod) {
+			return true
+		}
+	}
+	return false
+}
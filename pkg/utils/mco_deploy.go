@@ -11,7 +11,6 @@ import (
 	"fmt"
 	"io/ioutil"
 	"net/http"
-	"os"
 	"reflect"
 	"strings"
 
@@ -30,6 +29,7 @@ const (
 	OBSERVATORIUM_COMPONENT_LABEL = "app.kubernetes.io/part-of=observatorium"
 	MCO_NAMESPACE                 = "open-cluster-management-observability"
 	MCO_ADDON_NAMESPACE           = "open-cluster-management-addon-observability"
+	MCO_OPERATOR_LABEL            = "name=multicluster-observability-operator"
 	MCO_PULL_SECRET_NAME          = "multiclusterhub-operator-pull-secret"
 	OBJ_SECRET_NAME               = "thanos-object-storage"
 	MCO_GROUP                     = "observability.open-cluster-management.io"
@@ -108,6 +108,32 @@ func NewOCMMultiClusterHubGVR() schema.GroupVersionResource {
 		Resource: "multiclusterhubs"}
 }
 
+func NewPodsGVR() schema.GroupVersionResource {
+	return schema.GroupVersionResource{
+		Version:  "v1",
+		Resource: "pods"}
+}
+
+func NewConfigMapsGVR() schema.GroupVersionResource {
+	return schema.GroupVersionResource{
+		Version:  "v1",
+		Resource: "configmaps"}
+}
+
+func NewDeploymentsGVR() schema.GroupVersionResource {
+	return schema.GroupVersionResource{
+		Group:    "apps",
+		Version:  "v1",
+		Resource: "deployments"}
+}
+
+func NewClusterRoleBindingsGVR() schema.GroupVersionResource {
+	return schema.GroupVersionResource{
+		Group:    "rbac.authorization.k8s.io",
+		Version:  "v1",
+		Resource: "clusterrolebindings"}
+}
+
 func ModifyMCOAvailabilityConfig(opt TestOptions, availabilityConfig string) error {
 	clientDynamic := NewKubeClientDynamic(
 		opt.HubCluster.MasterURL,
@@ -139,18 +165,12 @@ func GetAllMCOPods(opt TestOptions) ([]corev1.Pod, error) {
 		return []corev1.Pod{}, err
 	}
 
-	// ignore non-mco pods
+	// ignore non-mco pods, e.g. grafana-test and minio
 	mcoPods := []corev1.Pod{}
 	for _, p := range podList.Items {
-		if strings.Contains(p.GetName(), "grafana-test") {
-			continue
+		if IsMCOPod(p) {
+			mcoPods = append(mcoPods, p)
 		}
-
-		if strings.Contains(p.GetName(), "minio") {
-			continue
-		}
-
-		mcoPods = append(mcoPods, p)
 	}
 
 	return mcoPods, nil
@@ -168,19 +188,26 @@ func PrintAllMCOPodsStatus(opt TestOptions) {
 
 	klog.V(1).Infof("Get <%v> pods in <%s> namespace", len(podList), MCO_NAMESPACE)
 	for _, pod := range podList {
-		isReady := false
-		if pod.Status.Phase == corev1.PodRunning {
-			isReady = true
-			break
-		}
-
 		// only print not ready pod status
-		if !isReady {
+		if !IsPodReady(pod) {
 			klog.V(1).Infof("Pod <%s> is not <Ready> on <%s> status due to %#v\n", pod.Name, pod.Status.Phase, pod.Status)
 		}
 	}
 }
 
+// IsPodReady returns true if the pod is running and all the containers are ready
+func IsPodReady(pod corev1.Pod) bool {
+	if pod.Status.Phase != corev1.PodRunning {
+		return false
+	}
+	for _, status := range pod.Status.ContainerStatuses {
+		if !status.Ready {
+			return false
+		}
+	}
+	return true
+}
+
 func PrintMCOObject(opt TestOptions) {
 	clientDynamic := NewKubeClientDynamic(
 		opt.HubCluster.MasterURL,
@@ -235,14 +262,8 @@ func PrintAllOBAPodsStatus(opt TestOptions) {
 	klog.V(1).Infof("Get <%v> pods in <%s> namespace from managedcluster", len(podList), MCO_ADDON_NAMESPACE)
 
 	for _, pod := range podList {
-		isReady := false
-		if pod.Status.Phase == corev1.PodRunning {
-			isReady = true
-			break
-		}
-
 		// only print not ready pod status
-		if !isReady {
+		if !IsPodReady(pod) {
 			klog.V(1).Infof("Pod <%s> is not <Ready> on <%s> status due to %#v\n", pod.Name, pod.Status.Phase, pod.Status)
 		}
 	}
@@ -273,144 +294,72 @@ func CheckAllPodsAffinity(opt TestOptions) error {
 	}
 
 	for _, pod := range podList {
-
-		if pod.Spec.Affinity == nil {
-			return fmt.Errorf("Failed to check affinity for pod: %v" + pod.GetName())
-		}
-
-		weightedPodAffinityTerms := pod.Spec.Affinity.PodAntiAffinity.PreferredDuringSchedulingIgnoredDuringExecution
-		for _, weightedPodAffinityTerm := range weightedPodAffinityTerms {
-			topologyKey := weightedPodAffinityTerm.PodAffinityTerm.TopologyKey
-			if (topologyKey == "kubernetes.io/hostname" && weightedPodAffinityTerm.Weight == 30) ||
-				(topologyKey == "topology.kubernetes.io/zone" && weightedPodAffinityTerm.Weight == 70) {
-			} else {
-				return fmt.Errorf("failed to check affinity for pod: %v" + pod.GetName())
-			}
+		if err := checkPodAntiAffinity(pod, false); err != nil {
+			return err
 		}
 	}
 	return nil
 }
 
-func CheckStorageResize(opt TestOptions, stsName string, expectedCapacity string) error {
-	client := getKubeClient(opt, true)
-	statefulsets := client.AppsV1().StatefulSets(MCO_NAMESPACE)
-	statefulset, err := statefulsets.Get(stsName, metav1.GetOptions{})
-	if err != nil {
-		klog.V(1).Infof("Error while retrieving statefulset %s: %s", stsName, err.Error())
-		return err
+// checkPodAntiAffinity checks the preferred anti-affinity terms of the pod spread the replicas across the
+// nodes and zones, requireTerms fails the check if any of the terms is missing
+func checkPodAntiAffinity(pod corev1.Pod, requireTerms bool) error {
+	if pod.Spec.Affinity == nil || pod.Spec.Affinity.PodAntiAffinity == nil {
+		return fmt.Errorf("failed to check affinity for pod: %v", pod.GetName())
 	}
-	vct := statefulset.Spec.VolumeClaimTemplates[0]
-	if !vct.Spec.Resources.Requests["storage"].Equal(resource.MustParse(expectedCapacity)) {
-		err = fmt.Errorf("the storage size of statefulset %s should have %s but got %v",
-			stsName, expectedCapacity,
-			vct.Spec.Resources.Requests["storage"])
-		return err
+
+	expectedWeights := map[string]int32{
+		HostnameTopologyKey: 30,
+		ZoneTopologyKey:     70,
+	}
+	found := map[string]bool{}
+	weightedPodAffinityTerms := pod.Spec.Affinity.PodAntiAffinity.PreferredDuringSchedulingIgnoredDuringExecution
+	for _, weightedPodAffinityTerm := range weightedPodAffinityTerms {
+		topologyKey := weightedPodAffinityTerm.PodAffinityTerm.TopologyKey
+		if weight, ok := expectedWeights[topologyKey]; !ok || weight != weightedPodAffinityTerm.Weight {
+			return fmt.Errorf("failed to check affinity for pod: %v", pod.GetName())
+		}
+		found[topologyKey] = true
+	}
+	if requireTerms {
+		for topologyKey := range expectedWeights {
+			if !found[topologyKey] {
+				return fmt.Errorf("pod %s has no anti-affinity term with topology key %s", pod.GetName(), topologyKey)
+			}
+		}
 	}
 	return nil
 }
 
 func CheckOBAComponents(opt TestOptions) error {
-	client := getKubeClient(opt, false)
-	deployments := client.AppsV1().Deployments(MCO_ADDON_NAMESPACE)
-	expectedDeploymentNames := []string{
-		"endpoint-observability-operator",
-		"metrics-collector-deployment",
-	}
-
-	for _, deploymentName := range expectedDeploymentNames {
-		deployment, err := deployments.Get(deploymentName, metav1.GetOptions{})
-		if err != nil {
-			klog.Errorf("Error while retrieving deployment %s: %s", deploymentName, err.Error())
-			return err
-		}
-
-		if deployment.Status.ReadyReplicas != *deployment.Spec.Replicas {
-			err = fmt.Errorf("deployment %s should have %d but got %d ready replicas",
-				deploymentName,
-				*deployment.Spec.Replicas,
-				deployment.Status.ReadyReplicas)
+	for _, component := range SpokeComponents() {
+		if err := component.CheckReady(opt); err != nil {
+			klog.Errorf("Failed to check %s due to %v", component.Name, err)
 			return err
 		}
 	}
-
 	return nil
 }
 
 func CheckMCOComponents(opt TestOptions) error {
-	client := NewKubeClient(
-		opt.HubCluster.MasterURL,
-		opt.KubeConfig,
-		opt.HubCluster.KubeContext)
-	deployments := client.AppsV1().Deployments(MCO_NAMESPACE)
-	expectedDeploymentLabels := []string{
-		"app=multicluster-observability-grafana",
-		"app.kubernetes.io/name=observatorium-api",
-		"app.kubernetes.io/name=thanos-query",
-		"app.kubernetes.io/name=thanos-query-frontend",
-		"app.kubernetes.io/name=thanos-receive-controller",
-		"app.kubernetes.io/name=observatorium-operator",
-		"app=rbac-query-proxy",
-	}
-
-	for _, deploymentLabel := range expectedDeploymentLabels {
-		deployList, err := deployments.List(metav1.ListOptions{
-			LabelSelector: deploymentLabel,
-		})
-
-		if err != nil {
-			klog.Errorf("Error while listing deployment with label %s due to: %s", deploymentLabel, err.Error())
+	for _, component := range HubComponents() {
+		if err := component.CheckReady(opt); err != nil {
+			klog.Errorf("Failed to check %s due to %v", component.Name, err)
 			return err
 		}
-
-		if len((*deployList).Items) == 0 {
-			return fmt.Errorf("should have deployment created with label %s", deploymentLabel)
-		}
-
-		for _, deployInfo := range (*deployList).Items {
-			if deployInfo.Status.ReadyReplicas != *deployInfo.Spec.Replicas {
-				err = fmt.Errorf("deployment %s should have %d but got %d ready replicas",
-					deployInfo.Name,
-					*deployInfo.Spec.Replicas,
-					deployInfo.Status.ReadyReplicas)
-				return err
-			}
-		}
 	}
+	return nil
+}
 
-	statefulsets := client.AppsV1().StatefulSets(MCO_NAMESPACE)
-	expectedStatefulsetLabels := []string{
-		"app=multicluster-observability-alertmanager",
-		"app.kubernetes.io/name=thanos-compact",
-		"app.kubernetes.io/name=thanos-receive",
-		"app.kubernetes.io/name=thanos-rule",
-		"app.kubernetes.io/name=memcached",
-		"app.kubernetes.io/name=thanos-store",
-	}
-
-	for _, statefulsetLabel := range expectedStatefulsetLabels {
-		statefulsetList, err := statefulsets.List(metav1.ListOptions{
-			LabelSelector: statefulsetLabel,
-		})
-		if err != nil {
-			klog.V(1).Infof("Error while listing deployment with label %s due to: %s", statefulsetLabel, err.Error())
+// CheckMCOComponentsRolledOut checks all hub components rolled out their latest spec, e.g. after the MCO CR
+// is changed
+func CheckMCOComponentsRolledOut(opt TestOptions) error {
+	for _, component := range HubComponents() {
+		if err := component.CheckRolledOut(opt); err != nil {
+			klog.V(1).Infof("The %s is not rolled out: %v", component.Name, err)
 			return err
 		}
-
-		if len((*statefulsetList).Items) == 0 {
-			return fmt.Errorf("should have statefulset created with label %s", statefulsetLabel)
-		}
-
-		for _, statefulsetInfo := range (*statefulsetList).Items {
-			if statefulsetInfo.Status.ReadyReplicas != *statefulsetInfo.Spec.Replicas {
-				err = fmt.Errorf("statefulset %s should have %d but got %d ready replicas",
-					statefulsetInfo.Name,
-					*statefulsetInfo.Spec.Replicas,
-					statefulsetInfo.Status.ReadyReplicas)
-				return err
-			}
-		}
 	}
-
 	return nil
 }
 
@@ -528,7 +477,11 @@ func ModifyMCOCR(opt TestOptions) error {
 
 	spec := mco.Object["spec"].(map[string]interface{})
 	storageConfig := spec["storageConfig"].(map[string]interface{})
-	storageConfig["alertmanagerStorageSize"] = "2Gi"
+	alertmanager, err := GetComponent("alertmanager")
+	if err != nil {
+		return err
+	}
+	storageConfig[alertmanager.StorageSizeKey] = "2Gi"
 
 	advRetentionCon, _ := CheckAdvRetentionConfig(opt)
 	if advRetentionCon {
@@ -725,6 +678,26 @@ func ModifyMCOAddonSpecMetrics(opt TestOptions, enable bool) error {
 	return nil
 }
 
+func GetMCOAddonSpecInterval(opt TestOptions) (int64, error) {
+	clientDynamic := NewKubeClientDynamic(
+		opt.HubCluster.MasterURL,
+		opt.KubeConfig,
+		opt.HubCluster.KubeContext)
+	mco, getErr := clientDynamic.Resource(NewMCOGVRV1BETA2()).Get(MCO_CR_NAME, metav1.GetOptions{})
+	if getErr != nil {
+		return 0, getErr
+	}
+
+	interval, found, err := unstructured.NestedInt64(mco.Object, "spec", "observabilityAddonSpec", "interval")
+	if err != nil {
+		return 0, err
+	}
+	if !found {
+		return 0, fmt.Errorf("the MCO CR did not have observabilityAddonSpec.interval spec configed")
+	}
+	return interval, nil
+}
+
 func ModifyMCOAddonSpecInterval(opt TestOptions, interval int64) error {
 	clientDynamic := NewKubeClientDynamic(
 		opt.HubCluster.MasterURL,
@@ -851,57 +824,6 @@ metadata:
 		[]byte(ns))
 }
 
-func CreateObjSecret(opt TestOptions) error {
-
-	bucket := os.Getenv("BUCKET")
-	if bucket == "" {
-		return fmt.Errorf("failed to get s3 BUCKET env")
-	}
-
-	region := os.Getenv("REGION")
-	if region == "" {
-		return fmt.Errorf("failed to get s3 REGION env")
-	}
-
-	accessKey := os.Getenv("AWS_ACCESS_KEY_ID")
-	if accessKey == "" {
-		return fmt.Errorf("failed to get aws AWS_ACCESS_KEY_ID env")
-	}
-
-	secretKey := os.Getenv("AWS_SECRET_ACCESS_KEY")
-	if secretKey == "" {
-		return fmt.Errorf("failed to get aws AWS_SECRET_ACCESS_KEY env")
-	}
-
-	objSecret := fmt.Sprintf(`apiVersion: v1
-kind: Secret
-metadata:
-  name: %s
-  namespace: %s
-stringData:
-  thanos.yaml: |
-    type: s3
-    config:
-      bucket: %s
-      endpoint: s3.%s.amazonaws.com
-      insecure: false
-      access_key: %s
-      secret_key: %s
-type: Opaque`,
-		OBJ_SECRET_NAME,
-		MCO_NAMESPACE,
-		bucket,
-		region,
-		accessKey,
-		secretKey)
-	klog.V(1).Infof("Create MCO object storage secret")
-	return Apply(
-		opt.HubCluster.MasterURL,
-		opt.KubeConfig,
-		opt.HubCluster.KubeContext,
-		[]byte(objSecret))
-}
-
 func UninstallMCO(opt TestOptions) error {
 	klog.V(1).Infof("Delete MCO instance")
 	deleteMCOErr := DeleteMCOInstance(opt)
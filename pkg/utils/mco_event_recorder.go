@@ -0,0 +1,210 @@
+// Copyright (c) 2021 Red Hat, Inc.
+// Copyright Contributors to the Open Cluster Management project
+
+package utils
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/klog"
+)
+
+// WarningEvent is a Warning event recorded with the spec running at the time, the repeats of the event
+// during the spec update the count and the time
+type WarningEvent struct {
+	UID types.UID
+	// FirstTime is the time the event was first seen, Time is the last time
+	FirstTime time.Time
+	Time      time.Time
+	Cluster   string
+	Namespace string
+	Kind      string
+	Object    string
+	Reason    string
+	Message   string
+	Count     int32
+	Spec      string
+}
+
+func (e WarningEvent) String() string {
+	return fmt.Sprintf("%s %s/%s %s/%s %s (x%d): %s", e.Time.UTC().Format(time.RFC3339), e.Cluster, e.Namespace,
+		e.Kind, e.Object, e.Reason, e.Count, e.Message)
+}
+
+// EventRecorder watches the events in MCO_NAMESPACE and MCO_ADDON_NAMESPACE on the hub and all managed
+// clusters, and records the Warning events with the spec running at the time
+type EventRecorder struct {
+	clients map[string]kubernetes.Interface
+
+	mu        sync.Mutex
+	spec      string
+	events    []WarningEvent
+	startedAt time.Time
+	stopCh    chan struct{}
+}
+
+// NewEventRecorder returns a recorder for the hub and the managed clusters in the options
+func NewEventRecorder(opt TestOptions) *EventRecorder {
+	return &EventRecorder{clients: getClusterClients(opt)}
+}
+
+// Start starts watching the events, the events happened before are ignored
+func (r *EventRecorder) Start() error {
+	r.stopCh = make(chan struct{})
+	r.startedAt = time.Now()
+	for cluster, client := range r.clients {
+		for _, ns := range []string{MCO_NAMESPACE, MCO_ADDON_NAMESPACE} {
+			factory := informers.NewSharedInformerFactoryWithOptions(client, 0, informers.WithNamespace(ns))
+			informer := factory.Core().V1().Events().Informer()
+			cluster := cluster
+			informer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+				AddFunc: func(obj interface{}) {
+					r.record(cluster, obj.(*corev1.Event))
+				},
+				UpdateFunc: func(_, obj interface{}) {
+					r.record(cluster, obj.(*corev1.Event))
+				},
+			})
+			factory.Start(r.stopCh)
+			if !cache.WaitForCacheSync(r.stopCh, informer.HasSynced) {
+				r.Stop()
+				return fmt.Errorf("failed to sync the events in namespace %s of cluster %s", ns, cluster)
+			}
+		}
+	}
+	return nil
+}
+
+// Stop stops watching the events
+func (r *EventRecorder) Stop() {
+	if r.stopCh != nil {
+		close(r.stopCh)
+		r.stopCh = nil
+	}
+}
+
+// SetSpec sets the spec the following events are attributed to
+func (r *EventRecorder) SetSpec(spec string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.spec = spec
+}
+
+// Persisted returns how long the event kept repeating
+func (e WarningEvent) Persisted() time.Duration {
+	return e.Time.Sub(e.FirstTime)
+}
+
+// Warnings returns the Warning events of the current spec with one of the reasons, all reasons if none
+// is given
+func (r *EventRecorder) Warnings(reasons ...string) []WarningEvent {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	warnings := []WarningEvent{}
+	for _, e := range r.events {
+		if e.Spec != r.spec {
+			continue
+		}
+		if len(reasons) == 0 || containsString(reasons, e.Reason) {
+			warnings = append(warnings, e)
+		}
+	}
+	sort.Slice(warnings, func(i, j int) bool { return warnings[i].Time.Before(warnings[j].Time) })
+	return warnings
+}
+
+// CheckNoWarnings returns an error listing the Warning events of the current spec with one of the reasons,
+// e.g. FailedScheduling, FailedMount, BackOff or FailedCreate
+func (r *EventRecorder) CheckNoWarnings(reasons ...string) error {
+	return checkWarnings(r.Warnings(reasons...))
+}
+
+// CheckNoPersistentWarnings is CheckNoWarnings ignoring the events which stopped repeating within the
+// duration, e.g. a FailedMount retried until the volume is attached
+func (r *EventRecorder) CheckNoPersistentWarnings(d time.Duration, reasons ...string) error {
+	warnings := []WarningEvent{}
+	for _, e := range r.Warnings(reasons...) {
+		if e.Persisted() >= d {
+			warnings = append(warnings, e)
+		}
+	}
+	return checkWarnings(warnings)
+}
+
+func checkWarnings(warnings []WarningEvent) error {
+	if len(warnings) == 0 {
+		return nil
+	}
+	msgs := []string{}
+	for _, e := range warnings {
+		msgs = append(msgs, e.String())
+	}
+	return fmt.Errorf("found %d warning events:\n%s", len(warnings), strings.Join(msgs, "\n"))
+}
+
+// PrintWarnings prints the Warning events of the current spec
+func (r *EventRecorder) PrintWarnings() {
+	warnings := r.Warnings()
+	klog.V(1).Infof("Get <%v> warning events", len(warnings))
+	for _, e := range warnings {
+		klog.V(1).Infof("Warning event: %s", e)
+	}
+}
+
+func (r *EventRecorder) record(cluster string, event *corev1.Event) {
+	if event.Type != corev1.EventTypeWarning {
+		return
+	}
+	ts := eventTime(*event)
+	if ts.Before(r.startedAt) {
+		return
+	}
+
+	firstTime := event.FirstTimestamp.Time
+	if firstTime.IsZero() || firstTime.After(ts) {
+		firstTime = ts
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	// the count of the event is bumped when it repeats
+	for i, e := range r.events {
+		if e.UID == event.UID && e.Cluster == cluster && e.Spec == r.spec {
+			r.events[i].Time, r.events[i].Count, r.events[i].Message = ts, event.Count, event.Message
+			return
+		}
+	}
+	warning := WarningEvent{
+		UID:       event.UID,
+		FirstTime: firstTime,
+		Time:      ts,
+		Cluster:   cluster,
+		Namespace: event.Namespace,
+		Kind:      event.InvolvedObject.Kind,
+		Object:    event.InvolvedObject.Name,
+		Reason:    event.Reason,
+		Message:   event.Message,
+		Count:     event.Count,
+		Spec:      r.spec,
+	}
+	klog.V(2).Infof("Observed warning event: %s", warning)
+	r.events = append(r.events, warning)
+}
+
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
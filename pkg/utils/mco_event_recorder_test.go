@@ -0,0 +1,61 @@
+// Copyright (c) 2021 Red Hat, Inc.
+// Copyright Contributors to the Open Cluster Management project
+
+package utils
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+func newWarningEvent(uid, reason string, first, last time.Time, count int32) *corev1.Event {
+	return &corev1.Event{
+		ObjectMeta:     metav1.ObjectMeta{UID: types.UID(uid), Namespace: MCO_NAMESPACE},
+		InvolvedObject: corev1.ObjectReference{Kind: "Pod", Name: "observability-thanos-receive-default-0"},
+		Type:           corev1.EventTypeWarning,
+		Reason:         reason,
+		FirstTimestamp: metav1.NewTime(first),
+		LastTimestamp:  metav1.NewTime(last),
+		Count:          count,
+	}
+}
+
+func TestEventRecorder(t *testing.T) {
+	start := time.Now().Truncate(time.Second)
+	r := &EventRecorder{startedAt: start}
+	r.SetSpec("spec-1")
+
+	// the repeats of an event update the recorded warning
+	r.record(HubClusterName, newWarningEvent("1", "FailedMount", start, start, 1))
+	r.record(HubClusterName, newWarningEvent("1", "FailedMount", start, start.Add(time.Minute), 2))
+	r.record(HubClusterName, newWarningEvent("1", "FailedMount", start, start.Add(3*time.Minute), 5))
+	r.record(HubClusterName, newWarningEvent("2", "FailedMount", start, start.Add(30*time.Second), 2))
+	r.record(HubClusterName, &corev1.Event{ObjectMeta: metav1.ObjectMeta{UID: "3"}, Type: corev1.EventTypeNormal, Reason: "Pulled"})
+
+	warnings := r.Warnings("FailedMount")
+	require.Len(t, warnings, 2)
+	assert.Equal(t, types.UID("1"), warnings[1].UID)
+	assert.Equal(t, int32(5), warnings[1].Count)
+	assert.Equal(t, 3*time.Minute, warnings[1].Persisted())
+	assert.Empty(t, r.Warnings("FailedScheduling"))
+
+	// only the event repeating for 2m persists
+	assert.Error(t, r.CheckNoWarnings("FailedMount"))
+	err := r.CheckNoPersistentWarnings(2*time.Minute, "FailedMount")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "found 1 warning events")
+	assert.NoError(t, r.CheckNoPersistentWarnings(5*time.Minute, "FailedMount"))
+
+	// the repeats in the next spec are recorded for the next spec
+	r.SetSpec("spec-2")
+	assert.Empty(t, r.Warnings())
+	r.record(HubClusterName, newWarningEvent("1", "FailedMount", start, start.Add(4*time.Minute), 6))
+	require.Len(t, r.Warnings(), 1)
+	assert.Equal(t, 4*time.Minute, r.Warnings()[0].Persisted())
+}
@@ -0,0 +1,98 @@
+// Copyright (c) 2021 Red Hat, Inc.
+// Copyright Contributors to the Open Cluster Management project
+
+package utils
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"k8s.io/klog"
+)
+
+// IngestionLatency holds the observed delays between a sample being produced on a managed cluster
+// and the sample being queryable on the hub
+type IngestionLatency struct {
+	Cluster   string
+	Latencies []time.Duration
+}
+
+// Quantile returns the q-quantile (0 <= q <= 1) of the observed latencies using the nearest-rank method
+func (l *IngestionLatency) Quantile(q float64) time.Duration {
+	if len(l.Latencies) == 0 {
+		return 0
+	}
+	sorted := make([]time.Duration, len(l.Latencies))
+	copy(sorted, l.Latencies)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	rank := int(math.Ceil(q*float64(len(sorted)))) - 1
+	if rank < 0 {
+		rank = 0
+	}
+	return sorted[rank]
+}
+
+// Max returns the max observed latency
+func (l *IngestionLatency) Max() time.Duration {
+	return l.Quantile(1)
+}
+
+// MeasureIngestionLatency polls the latest sample timestamp of the metric for each cluster during the given duration.
+// Every time a new sample becomes queryable, the difference between the poll time and the sample timestamp is recorded.
+// The first sample seen for each cluster is ignored since it is unknown when it became queryable, so the result is
+// accurate within the poll interval.
+func MeasureIngestionLatency(opt TestOptions, clusters []string, metric string, duration, interval time.Duration) (map[string]*IngestionLatency, error) {
+	if len(clusters) == 0 {
+		return nil, fmt.Errorf("no cluster is given to measure the ingestion latency")
+	}
+	query := fmt.Sprintf(`max by (cluster) (timestamp(%s{cluster=~"%s"}))`, metric, strings.Join(clusters, "|"))
+
+	latencies := map[string]*IngestionLatency{}
+	lastTimestamps := map[string]time.Time{}
+	for _, cluster := range clusters {
+		latencies[cluster] = &IngestionLatency{Cluster: cluster}
+	}
+
+	deadline := time.Now().Add(duration)
+	for time.Now().Before(deadline) {
+		result, err := QueryMetric(opt, query)
+		observed := time.Now()
+		if err != nil {
+			klog.V(1).Infof("Failed to query the latest sample timestamp: %v", err)
+		} else {
+			for _, series := range result.Data.Result {
+				cluster := series.Metric["cluster"]
+				latency, ok := latencies[cluster]
+				if !ok {
+					continue
+				}
+				sample, err := series.Sample()
+				if err != nil {
+					return nil, err
+				}
+				// the value of timestamp() is the sample timestamp in seconds
+				sec, frac := math.Modf(sample.Value)
+				ts := time.Unix(int64(sec), int64(frac*1e9))
+				last, seen := lastTimestamps[cluster]
+				if seen && ts.After(last) {
+					latency.Latencies = append(latency.Latencies, observed.Sub(ts))
+					klog.V(3).Infof("New sample of cluster %s is queryable after %v", cluster, observed.Sub(ts))
+				}
+				if !seen || ts.After(last) {
+					lastTimestamps[cluster] = ts
+				}
+			}
+		}
+		time.Sleep(interval)
+	}
+
+	for cluster, latency := range latencies {
+		if len(latency.Latencies) == 0 {
+			return latencies, fmt.Errorf("no new sample of %s is observed from cluster %s in %v", metric, cluster, duration)
+		}
+	}
+	return latencies, nil
+}
@@ -0,0 +1,304 @@
+// Copyright (c) 2021 Red Hat, Inc.
+// Copyright Contributors to the Open Cluster Management project
+
+package utils
+
+import (
+	"bufio"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/klog"
+)
+
+const (
+	// LogAllowlistEnv is the env of the file with the extra regular expressions of the log lines to ignore,
+	// one per line
+	LogAllowlistEnv = "LOG_ALLOWLIST"
+
+	// ErrorBurstPattern is the pattern name of the error bursts
+	ErrorBurstPattern  = "error burst"
+	defaultBurstLines  = 10
+	defaultBurstWindow = time.Minute
+)
+
+// LogPattern is a known bad pattern in the component logs
+type LogPattern struct {
+	Name   string
+	Regexp *regexp.Regexp
+}
+
+// DefaultLogPatterns are the patterns reported by the LogScanner
+var DefaultLogPatterns = []LogPattern{
+	{Name: "panic", Regexp: regexp.MustCompile(`^panic: |^fatal error: |goroutine \d+ \[running\]:`)},
+	{Name: "fatal", Regexp: regexp.MustCompile(`level=fatal|"level":"fatal"|^F\d{4} `)},
+	{Name: "reconcile error", Regexp: regexp.MustCompile(`Reconciler error|[Ff]ailed to reconcile`)},
+	{Name: "expired certificate", Regexp: regexp.MustCompile(`x509: certificate has expired or is not yet valid`)},
+}
+
+// DefaultLogAllowlist are the log lines ignored by the LogScanner
+var DefaultLogAllowlist = []string{
+	// the update conflicts are retried by the operators
+	`the object has been modified; please apply your changes to the latest version`,
+	`context canceled`,
+}
+
+var (
+	errorLineRegexp = regexp.MustCompile(`level=error|"level":"error"|^E\d{4} `)
+	logTimeRegexp   = regexp.MustCompile(`^(\d{4}-\d{2}-\d{2}T\S+)\s(.*)$`)
+)
+
+// LogMatch is a log line matching a bad pattern, the line is the first one of an error burst
+type LogMatch struct {
+	Cluster   string
+	Namespace string
+	Pod       string
+	Container string
+	Pattern   string
+	Time      time.Time
+	Line      string
+	// Count is the number of the lines in an error burst
+	Count int
+}
+
+func (m LogMatch) String() string {
+	source := fmt.Sprintf("%s/%s/%s/%s", m.Cluster, m.Namespace, m.Pod, m.Container)
+	if m.Pattern == ErrorBurstPattern {
+		return fmt.Sprintf("%s %s: %d error lines from %s", source, m.Pattern, m.Count, m.Line)
+	}
+	return fmt.Sprintf("%s %s: %s", source, m.Pattern, m.Line)
+}
+
+// LogScanner scans the logs of the MCO operator, the hub components and the addon components on all
+// clusters for panics, error bursts and the known bad patterns
+type LogScanner struct {
+	clients map[string]kubernetes.Interface
+
+	Patterns []LogPattern
+	// BurstLines error lines within BurstWindow are reported as an error burst
+	BurstLines  int
+	BurstWindow time.Duration
+
+	mu            sync.Mutex
+	allowlist     []*regexp.Regexp
+	allowed       []*regexp.Regexp
+	burstsAllowed bool
+}
+
+// NewLogScanner returns a scanner with the default patterns, the default allowlist and the allowlist
+// in the file of the LOG_ALLOWLIST env
+func NewLogScanner(opt TestOptions) (*LogScanner, error) {
+	s := &LogScanner{
+		clients:     getClusterClients(opt),
+		Patterns:    DefaultLogPatterns,
+		BurstLines:  defaultBurstLines,
+		BurstWindow: defaultBurstWindow,
+	}
+	allowlist := append([]string{}, DefaultLogAllowlist...)
+	if file := os.Getenv(LogAllowlistEnv); file != "" {
+		data, err := ioutil.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+		for _, line := range strings.Split(string(data), "\n") {
+			if line = strings.TrimSpace(line); line != "" && !strings.HasPrefix(line, "#") {
+				allowlist = append(allowlist, line)
+			}
+		}
+	}
+	for _, expr := range allowlist {
+		r, err := regexp.Compile(expr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid log allowlist %q: %v", expr, err)
+		}
+		s.allowlist = append(s.allowlist, r)
+	}
+	return s, nil
+}
+
+// Allow ignores the log lines matching the regular expressions until the next Reset
+func (s *LogScanner) Allow(exprs ...string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, expr := range exprs {
+		r, err := regexp.Compile(expr)
+		if err != nil {
+			return err
+		}
+		s.allowed = append(s.allowed, r)
+	}
+	return nil
+}
+
+// AllowBursts reports the error bursts without failing Check until the next Reset, for the specs breaking
+// the config on purpose, e.g. disabling the addon or deleting the manifestwork
+func (s *LogScanner) AllowBursts() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.burstsAllowed = true
+}
+
+// Reset clears the log lines allowed by Allow and the bursts allowed by AllowBursts
+func (s *LogScanner) Reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.allowed = nil
+	s.burstsAllowed = false
+}
+
+// Scan returns the matches in the logs since the time of all the containers
+func (s *LogScanner) Scan(since time.Time) ([]LogMatch, error) {
+	s.mu.Lock()
+	allowlist := append(append([]*regexp.Regexp{}, s.allowlist...), s.allowed...)
+	s.mu.Unlock()
+
+	matches := []LogMatch{}
+	for cluster, client := range s.clients {
+		pods, err := s.listPods(cluster, client)
+		if err != nil {
+			return nil, err
+		}
+		for _, pod := range pods {
+			for _, container := range pod.Spec.Containers {
+				logs, err := getPodLogs(client, pod.Namespace, pod.Name, container.Name, false, 0, since)
+				if err != nil {
+					// the pod may be deleted during the spec
+					klog.V(1).Infof("Skip scanning the logs of %s/%s/%s: %v", cluster, pod.Name, container.Name, err)
+					continue
+				}
+				for _, m := range ScanLogs(logs, s.Patterns, allowlist, s.BurstLines, s.BurstWindow) {
+					m.Cluster, m.Namespace, m.Pod, m.Container = cluster, pod.Namespace, pod.Name, container.Name
+					matches = append(matches, m)
+				}
+			}
+		}
+	}
+	sort.Slice(matches, func(i, j int) bool { return matches[i].Time.Before(matches[j].Time) })
+	return matches, nil
+}
+
+// Check returns an error listing the matches in the logs since the time
+func (s *LogScanner) Check(since time.Time) error {
+	matches, err := s.Scan(since)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	burstsAllowed := s.burstsAllowed
+	s.mu.Unlock()
+	return checkLogMatches(matches, burstsAllowed)
+}
+
+// checkLogMatches returns an error listing the matches, the error bursts are only logged if they are
+// allowed
+func checkLogMatches(matches []LogMatch, burstsAllowed bool) error {
+	msgs := []string{}
+	for _, m := range matches {
+		if burstsAllowed && m.Pattern == ErrorBurstPattern {
+			klog.Warningf("Ignore the error burst expected in the spec: %s", m)
+			continue
+		}
+		msgs = append(msgs, m.String())
+	}
+	if len(msgs) == 0 {
+		return nil
+	}
+	return fmt.Errorf("found %d issues in the component logs:\n%s", len(msgs), strings.Join(msgs, "\n"))
+}
+
+// listPods returns the MCO operator, MCO and addon pods on the cluster
+func (s *LogScanner) listPods(cluster string, client kubernetes.Interface) ([]corev1.Pod, error) {
+	pods := []corev1.Pod{}
+	if cluster == HubClusterName {
+		operators, err := client.CoreV1().Pods("").List(metav1.ListOptions{LabelSelector: MCO_OPERATOR_LABEL})
+		if err != nil {
+			return nil, err
+		}
+		pods = append(pods, operators.Items...)
+		mcoPods, err := client.CoreV1().Pods(MCO_NAMESPACE).List(metav1.ListOptions{})
+		if err != nil {
+			return nil, err
+		}
+		for _, pod := range mcoPods.Items {
+			if IsMCOPod(pod) {
+				pods = append(pods, pod)
+			}
+		}
+	}
+	addonPods, err := client.CoreV1().Pods(MCO_ADDON_NAMESPACE).List(metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	return append(pods, addonPods.Items...), nil
+}
+
+// ScanLogs returns the lines matching the patterns and the error bursts of more than burstLines error
+// lines within burstWindow, the lines matching the allowlist are ignored. The lines are expected to be
+// prefixed with the timestamps, otherwise the bursts are counted regardless of the time.
+func ScanLogs(logs string, patterns []LogPattern, allowlist []*regexp.Regexp, burstLines int, burstWindow time.Duration) []LogMatch {
+	matches := []LogMatch{}
+	burst := []LogMatch{}
+	burstIdx, lastError := -1, time.Time{}
+
+	scanner := bufio.NewScanner(strings.NewReader(logs))
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		ts, line := time.Time{}, scanner.Text()
+		if m := logTimeRegexp.FindStringSubmatch(line); m != nil {
+			if t, err := time.Parse(time.RFC3339Nano, m[1]); err == nil {
+				ts, line = t, m[2]
+			}
+		}
+		if isAllowed(line, allowlist) {
+			continue
+		}
+		for _, p := range patterns {
+			if p.Regexp.MatchString(line) {
+				matches = append(matches, LogMatch{Pattern: p.Name, Time: ts, Line: line})
+				break
+			}
+		}
+
+		if burstLines <= 0 || !errorLineRegexp.MatchString(line) {
+			continue
+		}
+		// the error lines following a reported burst within the window extend the burst
+		if burstIdx >= 0 && (ts.IsZero() || ts.Sub(lastError) <= burstWindow) {
+			matches[burstIdx].Count++
+			lastError = ts
+			continue
+		}
+		burstIdx = -1
+		burst = append(burst, LogMatch{Pattern: ErrorBurstPattern, Time: ts, Line: line})
+		for !ts.IsZero() && ts.Sub(burst[0].Time) > burstWindow {
+			burst = burst[1:]
+		}
+		if len(burst) >= burstLines {
+			first := burst[0]
+			first.Count = len(burst)
+			matches = append(matches, first)
+			burstIdx = len(matches) - 1
+			burst = nil
+		}
+		lastError = ts
+	}
+	return matches
+}
+
+func isAllowed(line string, allowlist []*regexp.Regexp) bool {
+	for _, r := range allowlist {
+		if r.MatchString(line) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,62 @@
+// Copyright (c) 2021 Red Hat, Inc.
+// Copyright Contributors to the Open Cluster Management project
+
+package utils
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestScanLogs(t *testing.T) {
+	start := time.Date(2021, 10, 1, 0, 0, 0, 0, time.UTC)
+	lines := []string{
+		"level=info msg=\"starting\"",
+		"panic: runtime error: invalid memory address or nil pointer dereference",
+		"level=error msg=\"Reconciler error\" err=\"the object has been modified; please apply your changes to the latest version\"",
+		"level=error msg=\"Reconciler error\" err=\"secret not found\"",
+	}
+	// a burst of 3 error lines within a minute and a single error line later
+	for i := 0; i < 3; i++ {
+		lines = append(lines, fmt.Sprintf("level=error msg=\"failed to forward request\" id=%d", i))
+	}
+	logs := ""
+	for i, line := range lines {
+		logs += start.Add(time.Duration(i)*time.Second).Format(time.RFC3339Nano) + " " + line + "\n"
+	}
+	logs += start.Add(10*time.Minute).Format(time.RFC3339Nano) + " level=error msg=\"failed to forward request\"\n"
+
+	allowlist := []*regexp.Regexp{regexp.MustCompile(DefaultLogAllowlist[0])}
+	matches := ScanLogs(logs, DefaultLogPatterns, allowlist, 3, time.Minute)
+	require.Len(t, matches, 3)
+	assert.Equal(t, "panic", matches[0].Pattern)
+	assert.Equal(t, start.Add(time.Second), matches[0].Time)
+	assert.Equal(t, "reconcile error", matches[1].Pattern)
+	assert.True(t, strings.Contains(matches[1].Line, "secret not found"))
+	assert.Equal(t, ErrorBurstPattern, matches[2].Pattern)
+	assert.Equal(t, 4, matches[2].Count)
+
+	// the burst is not reported if the threshold is not reached
+	matches = ScanLogs(logs, nil, allowlist, 5, time.Minute)
+	assert.Empty(t, matches)
+}
+
+func TestCheckLogMatches(t *testing.T) {
+	assert.NoError(t, checkLogMatches(nil, false))
+
+	burst := LogMatch{Cluster: "hub", Namespace: "ns", Pod: "pod", Container: "c", Pattern: ErrorBurstPattern, Line: "level=error", Count: 10}
+	assert.EqualError(t, checkLogMatches([]LogMatch{burst}, false),
+		"found 1 issues in the component logs:\nhub/ns/pod/c error burst: 10 error lines from level=error")
+	assert.NoError(t, checkLogMatches([]LogMatch{burst}, true))
+
+	// only the error bursts are allowed
+	panicMatch := LogMatch{Cluster: "hub", Namespace: "ns", Pod: "pod", Container: "c", Pattern: "panic", Line: "panic: boom"}
+	assert.EqualError(t, checkLogMatches([]LogMatch{burst, panicMatch}, true),
+		"found 1 issues in the component logs:\nhub/ns/pod/c panic: panic: boom")
+}
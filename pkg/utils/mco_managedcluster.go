@@ -101,3 +101,20 @@ func ListOCPManagedClusterIDs(opt TestOptions, minVersionStr string) ([]string,
 
 	return clusterIDs, nil
 }
+
+// ListManagedClusterNames returns the names of the managed clusters which have observability enabled
+func ListManagedClusterNames(opt TestOptions) ([]string, error) {
+	clientDynamic := GetKubeClientDynamic(opt, true)
+	objs, err := clientDynamic.Resource(NewOCMManagedClustersGVR()).List(metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	clusterNames := []string{}
+	for _, obj := range objs.Items {
+		if obj.GetLabels()["observability"] == "disabled" {
+			continue
+		}
+		clusterNames = append(clusterNames, obj.GetName())
+	}
+	return clusterNames, nil
+}
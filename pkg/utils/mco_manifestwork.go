@@ -0,0 +1,298 @@
+// Copyright (c) 2021 Red Hat, Inc.
+// Copyright Contributors to the Open Cluster Management project
+
+package utils
+
+import (
+	"encoding/base64"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/restmapper"
+)
+
+// ManifestState is the state of a manifest of a ManifestWork on the managed cluster
+type ManifestState string
+
+const (
+	// the live object matches the manifest
+	ManifestApplied ManifestState = "applied"
+	// the live object is not found or its kind is not served
+	ManifestMissing ManifestState = "missing"
+	// the work agent reports the manifest is not applied, not available or degraded
+	ManifestDegraded ManifestState = "degraded"
+	// the live object has fields different from the manifest
+	ManifestDrifted ManifestState = "drifted"
+)
+
+// the max number of the drifted fields reported for a manifest
+const maxDriftedFields = 5
+
+// ManifestResult is the state of a manifest with the reason when it is not applied
+type ManifestResult struct {
+	Kind      string
+	Namespace string
+	Name      string
+	State     ManifestState
+	Message   string
+}
+
+func (r ManifestResult) String() string {
+	name := r.Name
+	if r.Namespace != "" {
+		name = r.Namespace + "/" + name
+	}
+	s := fmt.Sprintf("%s %s is %s", r.Kind, name, r.State)
+	if r.Message != "" {
+		s += ": " + r.Message
+	}
+	return s
+}
+
+// ManifestWorkReport is the state of all manifests of a ManifestWork
+type ManifestWorkReport struct {
+	Namespace string
+	Name      string
+	Manifests []ManifestResult
+}
+
+// Failed returns the manifests which are missing, degraded or drifted
+func (r ManifestWorkReport) Failed() []ManifestResult {
+	failed := []ManifestResult{}
+	for _, m := range r.Manifests {
+		if m.State != ManifestApplied {
+			failed = append(failed, m)
+		}
+	}
+	return failed
+}
+
+// Error returns the error listing the failed manifests, nil if all manifests are applied
+func (r ManifestWorkReport) Error() error {
+	failed := r.Failed()
+	if len(failed) == 0 {
+		return nil
+	}
+	msgs := []string{fmt.Sprintf("%d of %d manifests of manifestwork %s/%s are not applied:",
+		len(failed), len(r.Manifests), r.Namespace, r.Name)}
+	for _, m := range failed {
+		msgs = append(msgs, "  "+m.String())
+	}
+	return fmt.Errorf("%s", strings.Join(msgs, "\n"))
+}
+
+// VerifyManifestWork compares each manifest in spec.workload.manifests of the ManifestWork in the namespace
+// of the managed cluster with its status conditions and the live object on the managed cluster of the name
+func VerifyManifestWork(opt TestOptions, clusterName, name string) (*ManifestWorkReport, error) {
+	work, err := GetKubeClientDynamic(opt, true).Resource(NewOCMManifestworksGVR()).Namespace(clusterName).Get(name, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+	kubeClient, err := GetManagedClusterKubeClient(opt, clusterName)
+	if err != nil {
+		return nil, err
+	}
+	dynamicClient, err := GetManagedClusterKubeClientDynamic(opt, clusterName)
+	if err != nil {
+		return nil, err
+	}
+	groupResources, err := restmapper.GetAPIGroupResources(kubeClient.Discovery())
+	if err != nil {
+		return nil, fmt.Errorf("failed to discover the resources of managed cluster %s: %v", clusterName, err)
+	}
+	mapper := restmapper.NewDiscoveryRESTMapper(groupResources)
+	return verifyManifests(work, mapper, dynamicClient)
+}
+
+func verifyManifests(work *unstructured.Unstructured, mapper meta.RESTMapper, client dynamic.Interface) (*ManifestWorkReport, error) {
+	manifests, _, err := unstructured.NestedSlice(work.Object, "spec", "workload", "manifests")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the manifests of manifestwork %s: %v", work.GetName(), err)
+	}
+	conditions := manifestConditions(work)
+
+	report := &ManifestWorkReport{Namespace: work.GetNamespace(), Name: work.GetName(), Manifests: []ManifestResult{}}
+	for i, m := range manifests {
+		manifest, ok := m.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("the manifest %d of manifestwork %s is not an object", i, work.GetName())
+		}
+		report.Manifests = append(report.Manifests, verifyManifest(unstructured.Unstructured{Object: manifest},
+			conditions[int64(i)], mapper, client))
+	}
+	return report, nil
+}
+
+// manifestConditions returns the conditions reported by the work agent by the ordinal of the manifests
+func manifestConditions(work *unstructured.Unstructured) map[int64][]interface{} {
+	conditions := map[int64][]interface{}{}
+	statuses, _, _ := unstructured.NestedSlice(work.Object, "status", "resourceStatus", "manifests")
+	for _, s := range statuses {
+		status, ok := s.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		ordinal, found, _ := unstructured.NestedInt64(status, "resourceMeta", "ordinal")
+		if !found {
+			continue
+		}
+		conditions[ordinal], _, _ = unstructured.NestedSlice(status, "conditions")
+	}
+	return conditions
+}
+
+func verifyManifest(manifest unstructured.Unstructured, conditions []interface{}, mapper meta.RESTMapper, client dynamic.Interface) ManifestResult {
+	result := ManifestResult{Kind: manifest.GetKind(), Namespace: manifest.GetNamespace(), Name: manifest.GetName()}
+
+	statuses := map[string]map[string]interface{}{}
+	for _, c := range conditions {
+		if condition, ok := c.(map[string]interface{}); ok {
+			statuses[fmt.Sprint(condition["type"])] = condition
+		}
+	}
+	if len(statuses) == 0 {
+		result.State, result.Message = ManifestDegraded, "no status reported by the work agent"
+		return result
+	}
+	for _, conditionType := range []string{"Applied", "Available"} {
+		if c, ok := statuses[conditionType]; ok && c["status"] != "True" {
+			result.State, result.Message = ManifestDegraded, fmt.Sprintf("%s=%v: %v", conditionType, c["status"], c["message"])
+			return result
+		}
+	}
+	if c, ok := statuses["Degraded"]; ok && c["status"] == "True" {
+		result.State, result.Message = ManifestDegraded, fmt.Sprintf("Degraded=True: %v", c["message"])
+		return result
+	}
+
+	gvk := manifest.GroupVersionKind()
+	mapping, err := mapper.RESTMapping(gvk.GroupKind(), gvk.Version)
+	if err != nil {
+		result.State, result.Message = ManifestMissing, fmt.Sprintf("%s is not served", gvk)
+		return result
+	}
+	resource := client.Resource(mapping.Resource)
+	var live *unstructured.Unstructured
+	if mapping.Scope.Name() == meta.RESTScopeNameNamespace {
+		live, err = resource.Namespace(manifest.GetNamespace()).Get(manifest.GetName(), metav1.GetOptions{})
+	} else {
+		live, err = resource.Get(manifest.GetName(), metav1.GetOptions{})
+	}
+	if errors.IsNotFound(err) {
+		result.State, result.Message = ManifestMissing, "not found"
+		return result
+	}
+	if err != nil {
+		result.State, result.Message = ManifestMissing, err.Error()
+		return result
+	}
+
+	if drifted := manifestDrift(manifest.Object, live.Object); len(drifted) > 0 {
+		result.State = ManifestDrifted
+		if len(drifted) > maxDriftedFields {
+			drifted = append(drifted[:maxDriftedFields], fmt.Sprintf("and %d more", len(drifted)-maxDriftedFields))
+		}
+		result.Message = strings.Join(drifted, ", ")
+		return result
+	}
+	result.State = ManifestApplied
+	return result
+}
+
+// manifestDrift returns the JSON pointers of the fields of the manifest which are different in the live
+// object, the fields only in the live object, e.g. the defaults, and the status are not drifts
+func manifestDrift(manifest, live map[string]interface{}) []string {
+	drifted := []string{}
+	for _, key := range sortedKeys(manifest) {
+		switch key {
+		case "status":
+			continue
+		case "metadata":
+			desired, _ := manifest[key].(map[string]interface{})
+			actual, _ := live[key].(map[string]interface{})
+			for _, field := range []string{"labels", "annotations"} {
+				if value, ok := desired[field]; ok {
+					drifted = append(drifted, diffValue("/metadata/"+field, value, actual[field])...)
+				}
+			}
+		case "stringData":
+			// the string data of the secrets is stored in the data
+			desired, _ := manifest[key].(map[string]interface{})
+			actual, _ := live["data"].(map[string]interface{})
+			for _, field := range sortedKeys(desired) {
+				encoded, _ := actual[field].(string)
+				decoded, err := base64.StdEncoding.DecodeString(encoded)
+				if err != nil || string(decoded) != fmt.Sprint(desired[field]) {
+					drifted = append(drifted, "/stringData/"+field)
+				}
+			}
+		default:
+			drifted = append(drifted, diffValue("/"+key, manifest[key], live[key])...)
+		}
+	}
+	return drifted
+}
+
+func diffValue(pointer string, desired, actual interface{}) []string {
+	switch d := desired.(type) {
+	case map[string]interface{}:
+		a, ok := actual.(map[string]interface{})
+		if !ok {
+			return []string{pointer}
+		}
+		drifted := []string{}
+		for _, key := range sortedKeys(d) {
+			token := strings.ReplaceAll(strings.ReplaceAll(key, "~", "~0"), "/", "~1")
+			drifted = append(drifted, diffValue(pointer+"/"+token, d[key], a[key])...)
+		}
+		return drifted
+	case []interface{}:
+		a, ok := actual.([]interface{})
+		if !ok || len(a) != len(d) {
+			return []string{pointer}
+		}
+		drifted := []string{}
+		for i := range d {
+			drifted = append(drifted, diffValue(pointer+"/"+strconv.Itoa(i), d[i], a[i])...)
+		}
+		return drifted
+	case nil:
+		return nil
+	}
+	if desiredNumber, ok := toFloat(desired); ok {
+		if actualNumber, ok := toFloat(actual); ok && desiredNumber == actualNumber {
+			return nil
+		}
+	}
+	if !reflect.DeepEqual(desired, actual) {
+		return []string{pointer}
+	}
+	return nil
+}
+
+func toFloat(value interface{}) (float64, bool) {
+	switch v := value.(type) {
+	case int64:
+		return float64(v), true
+	case float64:
+		return v, true
+	}
+	return 0, false
+}
+
+func sortedKeys(m map[string]interface{}) []string {
+	keys := []string{}
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
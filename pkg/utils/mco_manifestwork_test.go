@@ -0,0 +1,120 @@
+// Copyright (c) 2021 Red Hat, Inc.
+// Copyright Contributors to the Open Cluster Management project
+
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+)
+
+func newManifest(kind, name string, fields map[string]interface{}) map[string]interface{} {
+	obj := map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       kind,
+		"metadata": map[string]interface{}{
+			"name":      name,
+			"namespace": MCO_ADDON_NAMESPACE,
+			"labels":    map[string]interface{}{"owner": "observabilityaddon"},
+		},
+	}
+	for key, value := range fields {
+		obj[key] = value
+	}
+	return obj
+}
+
+func appliedCondition(ordinal int64, applied string) interface{} {
+	return map[string]interface{}{
+		"resourceMeta": map[string]interface{}{"ordinal": ordinal},
+		"conditions": []interface{}{
+			map[string]interface{}{"type": "Applied", "status": applied, "message": "apply failed"},
+		},
+	}
+}
+
+func TestVerifyManifests(t *testing.T) {
+	manifests := []interface{}{
+		newManifest("ConfigMap", "observability-metrics-allowlist", map[string]interface{}{
+			"data": map[string]interface{}{"metrics_list.yaml": "names: [up]"},
+		}),
+		newManifest("Secret", "observability-managed-cluster-certs", map[string]interface{}{
+			"stringData": map[string]interface{}{"ca.crt": "ca"},
+		}),
+		newManifest("ConfigMap", "observability-missing", nil),
+		newManifest("ConfigMap", "observability-not-applied", nil),
+		newManifest("Service", "observability-no-status", nil),
+	}
+	work := &unstructured.Unstructured{Object: map[string]interface{}{
+		"metadata": map[string]interface{}{"name": "endpoint-observability-work", "namespace": "cluster1"},
+		"spec":     map[string]interface{}{"workload": map[string]interface{}{"manifests": manifests}},
+		"status": map[string]interface{}{"resourceStatus": map[string]interface{}{"manifests": []interface{}{
+			appliedCondition(0, "True"),
+			appliedCondition(1, "True"),
+			appliedCondition(2, "True"),
+			appliedCondition(3, "False"),
+		}}},
+	}}
+
+	// the live objects are defaulted and the allowlist is changed manually
+	allowlist := newManifest("ConfigMap", "observability-metrics-allowlist", map[string]interface{}{
+		"data": map[string]interface{}{"metrics_list.yaml": "names: [up, other]"},
+	})
+	allowlist["metadata"].(map[string]interface{})["resourceVersion"] = "10"
+	secret := newManifest("Secret", "observability-managed-cluster-certs", map[string]interface{}{
+		"data": map[string]interface{}{"ca.crt": "Y2E="},
+		"type": "Opaque",
+	})
+	client := dynamicfake.NewSimpleDynamicClient(runtime.NewScheme(),
+		&unstructured.Unstructured{Object: allowlist}, &unstructured.Unstructured{Object: secret})
+
+	mapper := meta.NewDefaultRESTMapper(nil)
+	for _, kind := range []string{"ConfigMap", "Secret", "Service"} {
+		mapper.Add(schema.GroupVersionKind{Version: "v1", Kind: kind}, meta.RESTScopeNamespace)
+	}
+
+	report, err := verifyManifests(work, mapper, client)
+	require.NoError(t, err)
+	require.Len(t, report.Manifests, 5)
+	assert.Equal(t, ManifestDrifted, report.Manifests[0].State)
+	assert.Equal(t, "/data/metrics_list.yaml", report.Manifests[0].Message)
+	assert.Equal(t, ManifestApplied, report.Manifests[1].State)
+	assert.Equal(t, ManifestMissing, report.Manifests[2].State)
+	assert.Equal(t, ManifestDegraded, report.Manifests[3].State)
+	assert.Equal(t, "Applied=False: apply failed", report.Manifests[3].Message)
+	assert.Equal(t, ManifestDegraded, report.Manifests[4].State)
+
+	assert.Len(t, report.Failed(), 4)
+	assert.Contains(t, report.Error().Error(), "4 of 5 manifests of manifestwork cluster1/endpoint-observability-work are not applied")
+	assert.NoError(t, ManifestWorkReport{Manifests: report.Manifests[1:2]}.Error())
+}
+
+func TestManifestDrift(t *testing.T) {
+	manifest := map[string]interface{}{
+		"metadata": map[string]interface{}{"name": "a", "labels": map[string]interface{}{"app": "a"}},
+		"spec": map[string]interface{}{
+			"replicas":   int64(1),
+			"containers": []interface{}{map[string]interface{}{"name": "a"}},
+		},
+	}
+	live := map[string]interface{}{
+		"metadata": map[string]interface{}{"name": "a", "uid": "1", "labels": map[string]interface{}{"app": "a", "extra": "b"}},
+		"spec": map[string]interface{}{
+			"replicas":   float64(1),
+			"containers": []interface{}{map[string]interface{}{"name": "a", "image": "b"}},
+		},
+		"status": map[string]interface{}{"replicas": int64(1)},
+	}
+	assert.Empty(t, manifestDrift(manifest, live))
+
+	live["spec"].(map[string]interface{})["containers"] = []interface{}{}
+	live["metadata"].(map[string]interface{})["labels"] = map[string]interface{}{"app": "b"}
+	assert.Equal(t, []string{"/metadata/labels/app", "/spec/containers"}, manifestDrift(manifest, live))
+}
@@ -5,32 +5,99 @@ package utils
 
 import (
 	"crypto/tls"
+	"encoding/json"
 	"fmt"
 	"io/ioutil"
+	"math"
 	"net/http"
 	"net/url"
 	"os"
+	"strconv"
 	"strings"
+	"time"
 
 	"k8s.io/klog"
 )
 
-func ContainManagedClusterMetric(opt TestOptions, query string, matchedLabels []string) (error, bool) {
+// MetricQueryResult is the response of the prometheus HTTP query API
+type MetricQueryResult struct {
+	Status string `json:"status"`
+	Data   struct {
+		ResultType string         `json:"resultType"`
+		Result     []MetricSeries `json:"result"`
+	} `json:"data"`
+	Error string `json:"error,omitempty"`
+}
+
+// MetricSeries is a single series of an instant or range query result
+type MetricSeries struct {
+	Metric map[string]string `json:"metric"`
+	Value  []interface{}     `json:"value,omitempty"`
+	Values [][]interface{}   `json:"values,omitempty"`
+}
+
+// MetricSample is a single sample of a series
+type MetricSample struct {
+	Timestamp time.Time
+	Value     float64
+}
+
+// Sample returns the sample of an instant query series
+func (s MetricSeries) Sample() (MetricSample, error) {
+	return parseMetricSample(s.Value)
+}
+
+// Samples returns the samples of a range query series
+func (s MetricSeries) Samples() ([]MetricSample, error) {
+	samples := []MetricSample{}
+	for _, v := range s.Values {
+		sample, err := parseMetricSample(v)
+		if err != nil {
+			return nil, err
+		}
+		samples = append(samples, sample)
+	}
+	return samples, nil
+}
+
+func parseMetricSample(v []interface{}) (MetricSample, error) {
+	if len(v) != 2 {
+		return MetricSample{}, fmt.Errorf("invalid sample %v", v)
+	}
+	ts, ok := v[0].(float64)
+	if !ok {
+		return MetricSample{}, fmt.Errorf("invalid sample timestamp %v", v[0])
+	}
+	str, ok := v[1].(string)
+	if !ok {
+		return MetricSample{}, fmt.Errorf("invalid sample value %v", v[1])
+	}
+	val, err := strconv.ParseFloat(str, 64)
+	if err != nil {
+		return MetricSample{}, err
+	}
+	sec, frac := math.Modf(ts)
+	return MetricSample{
+		Timestamp: time.Unix(int64(sec), int64(math.Round(frac*1000))*int64(time.Millisecond)),
+		Value:     val,
+	}, nil
+}
+
+func doMetricQuery(opt TestOptions, api string, queryParams string) ([]byte, error) {
 	grafanaConsoleURL := GetGrafanaURL(opt)
-	path := "/api/datasources/proxy/1/api/v1/query?"
+	path := "/api/datasources/proxy/1/api/v1/" + api + "?"
 	// TODO(morvencao): remove this after accessing metrics from grafana url with bearer token is supported
 	if os.Getenv("IS_CANARY_ENV") != "true" && os.Getenv("THANOS_QUERY_FRONTEND_URL") != "" {
 		grafanaConsoleURL = os.Getenv("THANOS_QUERY_FRONTEND_URL")
-		path = "/api/v1/query?"
+		path = "/api/v1/" + api + "?"
 	}
-	queryParams := url.PathEscape(fmt.Sprintf("query=%s", query))
 	klog.V(5).Infof("request url is: %s\n", grafanaConsoleURL+path+queryParams)
 	req, err := http.NewRequest(
 		"GET",
 		grafanaConsoleURL+path+queryParams,
 		nil)
 	if err != nil {
-		return err, false
+		return nil, err
 	}
 
 	tr := &http.Transport{
@@ -40,7 +107,7 @@ func ContainManagedClusterMetric(opt TestOptions, query string, matchedLabels []
 	client := &http.Client{Transport: tr}
 	token, err := FetchBearerToken(opt)
 	if err != nil {
-		return err, false
+		return nil, err
 	}
 	if token != "" {
 		req.Header.Set("Authorization", "Bearer "+token)
@@ -49,16 +116,81 @@ func ContainManagedClusterMetric(opt TestOptions, query string, matchedLabels []
 
 	resp, err := client.Do(req)
 	if err != nil {
-		return err, false
+		return nil, err
 	}
+	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
 		klog.Errorf("resp.StatusCode: %v\n", resp.StatusCode)
-		return fmt.Errorf("Failed to access managed cluster metrics via grafana console"), false
+		return nil, fmt.Errorf("Failed to access managed cluster metrics via grafana console")
 	}
 
 	metricResult, err := ioutil.ReadAll(resp.Body)
 	klog.V(5).Infof("metricResult: %s\n", metricResult)
+	if err != nil {
+		return nil, err
+	}
+	return metricResult, nil
+}
+
+// QueryMetric runs an instant query and returns the parsed result
+func QueryMetric(opt TestOptions, query string) (*MetricQueryResult, error) {
+	return QueryMetricAt(opt, query, time.Time{})
+}
+
+// QueryMetricAt runs an instant query evaluated at the given time and returns the parsed result
+func QueryMetricAt(opt TestOptions, query string, ts time.Time) (*MetricQueryResult, error) {
+	return queryMetricAt(opt, query, ts, url.Values{})
+}
+
+// QueryMetricAtWithResolution runs an instant query with the max_source_resolution of thanos-query,
+// so that the data downsampled to the resolution is preferred and the coarser data is not used
+func QueryMetricAtWithResolution(opt TestOptions, query string, ts time.Time, resolution time.Duration) (*MetricQueryResult, error) {
+	params := url.Values{}
+	params.Set("max_source_resolution", strconv.FormatFloat(resolution.Seconds(), 'f', -1, 64))
+	return queryMetricAt(opt, query, ts, params)
+}
+
+func queryMetricAt(opt TestOptions, query string, ts time.Time, params url.Values) (*MetricQueryResult, error) {
+	params.Set("query", query)
+	if !ts.IsZero() {
+		params.Set("time", strconv.FormatFloat(float64(ts.UnixNano())/1e9, 'f', 3, 64))
+	}
+	metricResult, err := doMetricQuery(opt, "query", params.Encode())
+	if err != nil {
+		return nil, err
+	}
+	return parseMetricQueryResult(metricResult)
+}
+
+// QueryMetricRange runs a range query between start and end with the given step and returns the parsed result
+func QueryMetricRange(opt TestOptions, query string, start, end time.Time, step time.Duration) (*MetricQueryResult, error) {
+	params := url.Values{}
+	params.Set("query", query)
+	params.Set("start", strconv.FormatFloat(float64(start.UnixNano())/1e9, 'f', 3, 64))
+	params.Set("end", strconv.FormatFloat(float64(end.UnixNano())/1e9, 'f', 3, 64))
+	params.Set("step", strconv.FormatFloat(step.Seconds(), 'f', -1, 64))
+	metricResult, err := doMetricQuery(opt, "query_range", params.Encode())
+	if err != nil {
+		return nil, err
+	}
+	return parseMetricQueryResult(metricResult)
+}
+
+func parseMetricQueryResult(metricResult []byte) (*MetricQueryResult, error) {
+	result := &MetricQueryResult{}
+	if err := json.Unmarshal(metricResult, result); err != nil {
+		return nil, err
+	}
+	if result.Status != "success" {
+		return nil, fmt.Errorf("Failed to find valid status from response: %s", result.Error)
+	}
+	return result, nil
+}
+
+func ContainManagedClusterMetric(opt TestOptions, query string, matchedLabels []string) (error, bool) {
+	queryParams := url.PathEscape(fmt.Sprintf("query=%s", query))
+	metricResult, err := doMetricQuery(opt, "query", queryParams)
 	if err != nil {
 		return err, false
 	}
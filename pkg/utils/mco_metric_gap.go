@@ -0,0 +1,128 @@
+// Copyright (c) 2021 Red Hat, Inc.
+// Copyright Contributors to the Open Cluster Management project
+
+package utils
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// MetricGap is a time range in which no sample was received from a cluster
+type MetricGap struct {
+	Cluster string
+	Start   time.Time
+	End     time.Time
+}
+
+func (g MetricGap) Duration() time.Duration {
+	return g.End.Sub(g.Start)
+}
+
+func (g MetricGap) String() string {
+	return fmt.Sprintf("cluster %s has no data from %s to %s (%v)",
+		g.Cluster, g.Start.UTC().Format(time.RFC3339), g.End.UTC().Format(time.RFC3339), g.Duration())
+}
+
+// FindGaps returns every gap longer than maxGap between the sample timestamps within [start, end],
+// including the gap between start and the first sample. The window ends at the last sample since the
+// samples before end may not be ingested yet, it is one gap over the window when there is no sample.
+func FindGaps(cluster string, timestamps []time.Time, start, end time.Time, maxGap time.Duration) []MetricGap {
+	sorted := []time.Time{}
+	for _, ts := range timestamps {
+		if !ts.Before(start) && !ts.After(end) {
+			sorted = append(sorted, ts)
+		}
+	}
+	if len(sorted) == 0 {
+		return []MetricGap{{Cluster: cluster, Start: start, End: end}}
+	}
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Before(sorted[j]) })
+
+	gaps := []MetricGap{}
+	prev := start
+	for _, ts := range sorted {
+		if ts.Sub(prev) > maxGap {
+			gaps = append(gaps, MetricGap{Cluster: cluster, Start: prev, End: ts})
+		}
+		prev = ts
+	}
+	return gaps
+}
+
+// FindManagedClusterMetricGaps fetches the raw samples of the metric since start for each cluster and
+// returns every gap longer than maxGap. The samples of all series from the same cluster are merged, so a
+// gap means the cluster sent no sample of the metric at all. Every cluster should have a sample ingested
+// after resumedAt, e.g. the time the disruption is over, so that the gaps before resumedAt are all found.
+func FindManagedClusterMetricGaps(opt TestOptions, metric string, clusters []string, start, resumedAt time.Time, maxGap time.Duration) ([]MetricGap, error) {
+	if len(clusters) == 0 {
+		return nil, fmt.Errorf("no cluster is given to find the metric gaps")
+	}
+	now := time.Now()
+	window := int64(now.Sub(start).Seconds())
+	query := fmt.Sprintf(`%s{cluster=~"%s"}[%ds]`, metric, strings.Join(clusters, "|"), window)
+	result, err := QueryMetricAt(opt, query, now)
+	if err != nil {
+		return nil, err
+	}
+
+	timestamps := map[string][]time.Time{}
+	for _, series := range result.Data.Result {
+		samples, err := series.Samples()
+		if err != nil {
+			return nil, err
+		}
+		cluster := series.Metric["cluster"]
+		for _, sample := range samples {
+			timestamps[cluster] = append(timestamps[cluster], sample.Timestamp)
+		}
+	}
+
+	gaps := []MetricGap{}
+	for _, cluster := range clusters {
+		resumed := false
+		for _, ts := range timestamps[cluster] {
+			if ts.After(resumedAt) {
+				resumed = true
+				break
+			}
+		}
+		if !resumed {
+			return nil, fmt.Errorf("cluster %s has no sample of %s ingested after %s", cluster, metric, resumedAt.UTC().Format(time.RFC3339))
+		}
+		gaps = append(gaps, FindGaps(cluster, timestamps[cluster], start, now, maxGap)...)
+	}
+	return gaps, nil
+}
+
+// GetMetricGapThreshold returns the max allowed gap which is factor times the collector interval of the MCO CR
+func GetMetricGapThreshold(opt TestOptions, factor int64) (time.Duration, error) {
+	interval, err := GetMCOAddonSpecInterval(opt)
+	if err != nil {
+		return 0, err
+	}
+	return time.Duration(factor*interval) * time.Second, nil
+}
+
+// CheckManagedClusterMetricContinuity returns an error listing the gaps of the metric since start which are
+// longer than factor times the collector interval, or when a cluster has no sample ingested after resumedAt
+func CheckManagedClusterMetricContinuity(opt TestOptions, metric string, clusters []string, start, resumedAt time.Time, factor int64) error {
+	maxGap, err := GetMetricGapThreshold(opt, factor)
+	if err != nil {
+		return err
+	}
+	gaps, err := FindManagedClusterMetricGaps(opt, metric, clusters, start, resumedAt, maxGap)
+	if err != nil {
+		return err
+	}
+	if len(gaps) > 0 {
+		msgs := []string{}
+		for _, gap := range gaps {
+			msgs = append(msgs, gap.String())
+		}
+		return fmt.Errorf("found %d gaps longer than %v in %s: %s", len(gaps), maxGap, metric, strings.Join(msgs, "; "))
+	}
+	return nil
+}
@@ -0,0 +1,30 @@
+// Copyright (c) 2021 Red Hat, Inc.
+// Copyright Contributors to the Open Cluster Management project
+
+package utils
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFindGaps(t *testing.T) {
+	start := time.Unix(1600000000, 0)
+	at := func(sec int) time.Time { return start.Add(time.Duration(sec) * time.Second) }
+	end := at(300)
+
+	// unordered samples every 30s, missing the ones between 90s and 210s, with one sample out of the window
+	timestamps := []time.Time{at(210), at(0), at(30), at(60), at(90), at(240), at(270), at(300), at(330)}
+	gaps := FindGaps("cluster1", timestamps, start, end, 90*time.Second)
+	assert.Equal(t, []MetricGap{{Cluster: "cluster1", Start: at(90), End: at(210)}}, gaps, "interior gap")
+
+	// the window ends at the last sample
+	gaps = FindGaps("cluster1", []time.Time{at(120), at(150)}, start, end, 90*time.Second)
+	assert.Equal(t, []MetricGap{{Cluster: "cluster1", Start: at(0), End: at(120)}}, gaps, "leading edge gap")
+
+	gaps = FindGaps("cluster1", nil, start, end, 90*time.Second)
+	assert.Equal(t, []MetricGap{{Cluster: "cluster1", Start: start, End: end}}, gaps, "no samples")
+	assert.Equal(t, 300*time.Second, gaps[0].Duration())
+}
@@ -0,0 +1,320 @@
+// Copyright (c) 2021 Red Hat, Inc.
+// Copyright Contributors to the Open Cluster Management project
+
+package utils
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/ghodss/yaml"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog"
+)
+
+const (
+	AllowlistConfigMapName       = "observability-metrics-allowlist"
+	AllowlistCustomConfigMapName = "observability-metrics-custom-allowlist"
+	AllowlistConfigMapKey        = "metrics_list.yaml"
+	ThanosRuleDefaultRules       = "thanos-ruler-default-rules"
+	ThanosRuleDefaultRulesKey    = "default_rules.yaml"
+)
+
+var matchNameRegexp = regexp.MustCompile(`__name__="([^"]+)"`)
+
+// MetricsAllowlist is the content of the metrics allowlist configmaps
+type MetricsAllowlist struct {
+	Names          []string          `json:"names,omitempty"`
+	Matches        []string          `json:"matches,omitempty"`
+	Renames        map[string]string `json:"renames,omitempty"`
+	RecordingRules []RecordingRule   `json:"recording_rules,omitempty"`
+}
+
+type RecordingRule struct {
+	Record string `json:"record"`
+	Expr   string `json:"expr"`
+}
+
+// AllowlistReport is the result of verifying the metrics of a cluster against the allowlist
+type AllowlistReport struct {
+	Cluster string
+	// allowlisted metrics which are not found on the hub
+	Missing []string
+	// renamed metrics which are still found under the old name
+	NotRenamed []string
+	// metrics which are found on the hub but are not allowlisted
+	Leaked []string
+}
+
+func (r AllowlistReport) Error() error {
+	if len(r.Missing) == 0 && len(r.NotRenamed) == 0 && len(r.Leaked) == 0 {
+		return nil
+	}
+	return fmt.Errorf("cluster %s has missing metrics: %v, not renamed metrics: %v, not allowlisted metrics: %v",
+		r.Cluster, r.Missing, r.NotRenamed, r.Leaked)
+}
+
+// GetMetricsAllowlist parses the allowlist configmap with the given name and namespace
+func GetMetricsAllowlist(opt TestOptions, isHub bool, name, namespace string) (*MetricsAllowlist, error) {
+	err, cm := GetConfigMap(opt, isHub, name, namespace)
+	if err != nil {
+		return nil, err
+	}
+	allowlist := &MetricsAllowlist{}
+	if err := yaml.Unmarshal([]byte(cm.Data[AllowlistConfigMapKey]), allowlist); err != nil {
+		return nil, fmt.Errorf("failed to parse %s in configmap %s/%s: %v", AllowlistConfigMapKey, namespace, name, err)
+	}
+	return allowlist, nil
+}
+
+// MergeMetricsAllowlist merges the custom allowlists into the default one. The names prefixed
+// with "-" in the custom allowlists are removed from the result.
+func MergeMetricsAllowlist(allowlist *MetricsAllowlist, customAllowlists ...*MetricsAllowlist) *MetricsAllowlist {
+	merged := &MetricsAllowlist{Renames: map[string]string{}}
+	removed := map[string]bool{}
+	for _, l := range append([]*MetricsAllowlist{allowlist}, customAllowlists...) {
+		if l == nil {
+			continue
+		}
+		for _, name := range l.Names {
+			if strings.HasPrefix(name, "-") {
+				removed[strings.TrimPrefix(name, "-")] = true
+				continue
+			}
+			merged.Names = append(merged.Names, name)
+		}
+		for _, match := range l.Matches {
+			if strings.HasPrefix(match, "-") {
+				removed[strings.TrimPrefix(match, "-")] = true
+				continue
+			}
+			merged.Matches = append(merged.Matches, match)
+		}
+		for k, v := range l.Renames {
+			merged.Renames[k] = v
+		}
+		merged.RecordingRules = append(merged.RecordingRules, l.RecordingRules...)
+	}
+
+	names := []string{}
+	for _, name := range merged.Names {
+		if !removed[name] {
+			names = append(names, name)
+		}
+	}
+	merged.Names = names
+	matches := []string{}
+	for _, match := range merged.Matches {
+		if !removed[match] {
+			matches = append(matches, match)
+		}
+	}
+	merged.Matches = matches
+	return merged
+}
+
+// ExpectedMetricNames returns the names of the metrics sent to the hub, with the renames applied
+func (l *MetricsAllowlist) ExpectedMetricNames() []string {
+	names := map[string]bool{}
+	for _, name := range l.Names {
+		if newName, ok := l.Renames[name]; ok {
+			name = newName
+		}
+		names[name] = true
+	}
+	for _, match := range l.Matches {
+		if m := matchNameRegexp.FindStringSubmatch(match); m != nil {
+			names[m[1]] = true
+		}
+	}
+	for _, rule := range l.RecordingRules {
+		names[rule.Record] = true
+	}
+	result := []string{}
+	for name := range names {
+		result = append(result, name)
+	}
+	sort.Strings(result)
+	return result
+}
+
+// GetThanosRuleRecordNames returns the names of the metrics recorded by the default thanos rules on the hub
+func GetThanosRuleRecordNames(opt TestOptions) ([]string, error) {
+	err, cm := GetConfigMap(opt, true, ThanosRuleDefaultRules, MCO_NAMESPACE)
+	if err != nil {
+		return nil, err
+	}
+	rules := struct {
+		Groups []struct {
+			Rules []struct {
+				Record string `json:"record,omitempty"`
+				Alert  string `json:"alert,omitempty"`
+			} `json:"rules"`
+		} `json:"groups"`
+	}{}
+	if err := yaml.Unmarshal([]byte(cm.Data[ThanosRuleDefaultRulesKey]), &rules); err != nil {
+		return nil, err
+	}
+	names := []string{}
+	for _, group := range rules.Groups {
+		for _, rule := range group.Rules {
+			if rule.Record != "" {
+				names = append(names, rule.Record)
+			}
+		}
+	}
+	return names, nil
+}
+
+// GetManagedClusterMetricNames returns the names of all metrics with the cluster label on the hub, the
+// synthetic series pushed by the e2e remote write are not collected from the cluster and not returned
+func GetManagedClusterMetricNames(opt TestOptions, cluster string) (map[string]bool, error) {
+	result, err := QueryMetric(opt, fmt.Sprintf(`count by (__name__) ({cluster="%s",%s=""})`, cluster, SyntheticRunLabel))
+	if err != nil {
+		return nil, err
+	}
+	names := map[string]bool{}
+	for _, series := range result.Data.Result {
+		names[series.Metric["__name__"]] = true
+	}
+	return names, nil
+}
+
+// GetManagedClusterCustomAllowlist parses the custom allowlist configmap in the addon namespace of the
+// managed cluster, nil is returned if it is not found
+func GetManagedClusterCustomAllowlist(opt TestOptions, clusterName string) (*MetricsAllowlist, error) {
+	clientKube, err := GetManagedClusterKubeClient(opt, clusterName)
+	if err != nil {
+		return nil, err
+	}
+	cm, err := clientKube.CoreV1().ConfigMaps(MCO_ADDON_NAMESPACE).Get(AllowlistCustomConfigMapName, metav1.GetOptions{})
+	if errors.IsNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		klog.Errorf("Failed to get custom allowlist configmap in cluster %s due to %v", clusterName, err)
+		return nil, err
+	}
+	allowlist := &MetricsAllowlist{}
+	if err := yaml.Unmarshal([]byte(cm.Data[AllowlistConfigMapKey]), allowlist); err != nil {
+		return nil, fmt.Errorf("failed to parse %s in configmap %s of cluster %s: %v", AllowlistConfigMapKey, cm.Name, clusterName, err)
+	}
+	return allowlist, nil
+}
+
+// VerifyMetricsAllowlist checks the metrics of each cluster on the hub against the allowlist merged with
+// the custom allowlist of the cluster. The ignoredNames are metrics which are not collected from the
+// clusters but carry the cluster label, e.g. ALERTS or the metrics recorded by thanos rule on the hub.
+func VerifyMetricsAllowlist(opt TestOptions, allowlist *MetricsAllowlist, clusters []string, ignoredNames []string) ([]AllowlistReport, error) {
+	ignoredSet := map[string]bool{}
+	for _, name := range ignoredNames {
+		ignoredSet[name] = true
+	}
+
+	reports := []AllowlistReport{}
+	for _, cluster := range clusters {
+		customAllowlist, err := GetManagedClusterCustomAllowlist(opt, cluster)
+		if err != nil {
+			return nil, err
+		}
+		clusterAllowlist := MergeMetricsAllowlist(allowlist, customAllowlist)
+		expected := clusterAllowlist.ExpectedMetricNames()
+		expectedSet := map[string]bool{}
+		for _, name := range expected {
+			expectedSet[name] = true
+		}
+
+		names, err := GetManagedClusterMetricNames(opt, cluster)
+		if err != nil {
+			return nil, err
+		}
+		report := AllowlistReport{Cluster: cluster}
+		for _, name := range expected {
+			if !names[name] {
+				report.Missing = append(report.Missing, name)
+			}
+		}
+		for _, match := range clusterAllowlist.Matches {
+			result, err := QueryMetric(opt, fmt.Sprintf(`count({%s,cluster="%s"})`, match, cluster))
+			if err != nil {
+				return nil, err
+			}
+			if len(result.Data.Result) == 0 {
+				report.Missing = append(report.Missing, "{"+match+"}")
+			}
+		}
+		for oldName, newName := range clusterAllowlist.Renames {
+			if names[oldName] && !expectedSet[oldName] {
+				report.NotRenamed = append(report.NotRenamed, oldName+"="+newName)
+			}
+		}
+		for name := range names {
+			if !expectedSet[name] && !ignoredSet[name] {
+				report.Leaked = append(report.Leaked, name)
+			}
+		}
+		sort.Strings(report.NotRenamed)
+		sort.Strings(report.Leaked)
+		klog.V(1).Infof("Verified %d metrics of cluster %s against %d allowlisted metrics", len(names), cluster, len(expected))
+		reports = append(reports, report)
+	}
+	return reports, nil
+}
+
+// CreateManagedClusterCustomAllowlist creates the custom allowlist configmap in the addon namespace
+// of the managed cluster, which only applies to the metrics collected from that cluster
+func CreateManagedClusterCustomAllowlist(opt TestOptions, clusterName string, allowlist *MetricsAllowlist) error {
+	clientKube, err := GetManagedClusterKubeClient(opt, clusterName)
+	if err != nil {
+		return err
+	}
+	data, err := yaml.Marshal(allowlist)
+	if err != nil {
+		return err
+	}
+	cm := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      AllowlistCustomConfigMapName,
+			Namespace: MCO_ADDON_NAMESPACE,
+		},
+		Data: map[string]string{AllowlistConfigMapKey: string(data)},
+	}
+	found, err := clientKube.CoreV1().ConfigMaps(MCO_ADDON_NAMESPACE).Get(cm.Name, metav1.GetOptions{})
+	if err != nil && errors.IsNotFound(err) {
+		_, err = clientKube.CoreV1().ConfigMaps(MCO_ADDON_NAMESPACE).Create(cm)
+	} else if err == nil {
+		cm.ResourceVersion = found.ResourceVersion
+		_, err = clientKube.CoreV1().ConfigMaps(MCO_ADDON_NAMESPACE).Update(cm)
+	}
+	if err != nil {
+		klog.Errorf("Failed to create custom allowlist configmap in cluster %s due to %v", clusterName, err)
+	}
+	return err
+}
+
+// DeleteManagedClusterCustomAllowlist deletes the custom allowlist configmap from the managed cluster
+func DeleteManagedClusterCustomAllowlist(opt TestOptions, clusterName string) error {
+	clientKube, err := GetManagedClusterKubeClient(opt, clusterName)
+	if err != nil {
+		return err
+	}
+	err = clientKube.CoreV1().ConfigMaps(MCO_ADDON_NAMESPACE).Delete(AllowlistCustomConfigMapName, &metav1.DeleteOptions{})
+	if err != nil && !errors.IsNotFound(err) {
+		klog.Errorf("Failed to delete custom allowlist configmap in cluster %s due to %v", clusterName, err)
+		return err
+	}
+	return nil
+}
+
+// ManagedClusterHasMetric checks whether the metric of the cluster is found on the hub
+func ManagedClusterHasMetric(opt TestOptions, clusterName, metric string) (bool, error) {
+	result, err := QueryMetric(opt, fmt.Sprintf(`%s{cluster="%s"}`, metric, clusterName))
+	if err != nil {
+		return false, err
+	}
+	return len(result.Data.Result) > 0, nil
+}
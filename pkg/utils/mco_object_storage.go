@@ -0,0 +1,255 @@
+// Copyright (c) 2021 Red Hat, Inc.
+// Copyright Contributors to the Open Cluster Management project
+
+package utils
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	"github.com/ghodss/yaml"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/klog"
+)
+
+const (
+	ObjStorageProviderAWS   = "aws"
+	ObjStorageProviderGCP   = "gcp"
+	ObjStorageProviderAzure = "azure"
+
+	OBJ_SECRET_KEY            = "thanos.yaml"
+	OBJ_TLS_SECRET_NAME       = "thanos-object-storage-tls"
+	OBJ_TLS_SECRET_MOUNT_PATH = "/etc/thanos/certs"
+	defaultAzureEndpoint      = "blob.core.windows.net"
+)
+
+// objStorageConfig is the thanos object storage configuration
+type objStorageConfig struct {
+	Type   string      `json:"type"`
+	Config interface{} `json:"config"`
+}
+
+type s3Config struct {
+	Bucket     string        `json:"bucket"`
+	Endpoint   string        `json:"endpoint"`
+	Region     string        `json:"region,omitempty"`
+	Insecure   bool          `json:"insecure"`
+	AccessKey  string        `json:"access_key"`
+	SecretKey  string        `json:"secret_key"`
+	HTTPConfig *s3HTTPConfig `json:"http_config,omitempty"`
+}
+
+type s3HTTPConfig struct {
+	InsecureSkipVerify bool         `json:"insecure_skip_verify,omitempty"`
+	TLSConfig          *s3TLSConfig `json:"tls_config,omitempty"`
+}
+
+type s3TLSConfig struct {
+	CAFile string `json:"ca_file,omitempty"`
+}
+
+type gcsConfig struct {
+	Bucket         string `json:"bucket"`
+	ServiceAccount string `json:"service_account"`
+}
+
+type azureConfig struct {
+	StorageAccount    string `json:"storage_account"`
+	StorageAccountKey string `json:"storage_account_key"`
+	Container         string `json:"container"`
+	Endpoint          string `json:"endpoint"`
+	MaxRetries        int    `json:"max_retries"`
+}
+
+// GetObjStorageProvider returns the configured provider, or the first one with a bucket configured
+func GetObjStorageProvider(opt TestOptions) string {
+	if opt.Connection.ObjectStorageProvider != "" {
+		return opt.Connection.ObjectStorageProvider
+	}
+	keys := opt.Connection.Keys
+	switch {
+	case keys.AWS.Bucket != "":
+		return ObjStorageProviderAWS
+	case keys.GCP.Bucket != "":
+		return ObjStorageProviderGCP
+	case keys.Azure.Container != "":
+		return ObjStorageProviderAzure
+	}
+	return ObjStorageProviderAWS
+}
+
+// GetObjStorageConfig returns the thanos object storage configuration of the provider, and the CA
+// certificate to be mounted into the thanos components if there is one
+func GetObjStorageConfig(opt TestOptions) ([]byte, []byte, error) {
+	var config objStorageConfig
+	var caCert []byte
+	keys := opt.Connection.Keys
+	switch provider := GetObjStorageProvider(opt); provider {
+	case ObjStorageProviderAWS:
+		s3, err := getS3Config(keys.AWS)
+		if err != nil {
+			return nil, nil, err
+		}
+		if keys.AWS.InsecureSkipVerify {
+			s3.HTTPConfig = &s3HTTPConfig{InsecureSkipVerify: true}
+		}
+		if hasObjStorageCACert(opt) {
+			caCert, err = ioutil.ReadFile(keys.AWS.CACert)
+			if err != nil {
+				return nil, nil, fmt.Errorf("failed to read s3 CA certificate %s: %v", keys.AWS.CACert, err)
+			}
+			if s3.HTTPConfig == nil {
+				s3.HTTPConfig = &s3HTTPConfig{}
+			}
+			s3.HTTPConfig.TLSConfig = &s3TLSConfig{CAFile: OBJ_TLS_SECRET_MOUNT_PATH + "/ca.crt"}
+		}
+		config = objStorageConfig{Type: "s3", Config: s3}
+	case ObjStorageProviderGCP:
+		if keys.GCP.Bucket == "" || keys.GCP.ServiceAccountJsonKey == "" {
+			return nil, nil, fmt.Errorf("failed to get gcs bucket or service account key from options")
+		}
+		config = objStorageConfig{Type: "gcs", Config: gcsConfig{
+			Bucket:         keys.GCP.Bucket,
+			ServiceAccount: keys.GCP.ServiceAccountJsonKey,
+		}}
+	case ObjStorageProviderAzure:
+		if keys.Azure.StorageAccount == "" || keys.Azure.StorageAccountKey == "" || keys.Azure.Container == "" {
+			return nil, nil, fmt.Errorf("failed to get azure storage account, key or container from options")
+		}
+		endpoint := keys.Azure.Endpoint
+		if endpoint == "" {
+			endpoint = defaultAzureEndpoint
+		}
+		config = objStorageConfig{Type: "azure", Config: azureConfig{
+			StorageAccount:    keys.Azure.StorageAccount,
+			StorageAccountKey: keys.Azure.StorageAccountKey,
+			Container:         keys.Azure.Container,
+			Endpoint:          endpoint,
+		}}
+	default:
+		return nil, nil, fmt.Errorf("unsupported object storage provider %s", provider)
+	}
+
+	data, err := yaml.Marshal(config)
+	if err != nil {
+		return nil, nil, err
+	}
+	return data, caCert, nil
+}
+
+// hasObjStorageCACert returns true if the CA certificate of the S3 compatible endpoint is configured
+func hasObjStorageCACert(opt TestOptions) bool {
+	return GetObjStorageProvider(opt) == ObjStorageProviderAWS && opt.Connection.Keys.AWS.CACert != ""
+}
+
+// getS3Config builds the s3 configuration from the options, the BUCKET, REGION, AWS_ACCESS_KEY_ID and
+// AWS_SECRET_ACCESS_KEY env are used when the options are not set
+func getS3Config(key AWSAPIKey) (*s3Config, error) {
+	s3 := &s3Config{
+		Bucket:    key.Bucket,
+		Endpoint:  key.Endpoint,
+		Region:    key.Region,
+		Insecure:  key.Insecure,
+		AccessKey: key.AWSAccessID,
+		SecretKey: key.AWSAccessSecret,
+	}
+	if s3.Bucket == "" {
+		s3.Bucket = os.Getenv("BUCKET")
+	}
+	if s3.Region == "" {
+		s3.Region = os.Getenv("REGION")
+	}
+	if s3.AccessKey == "" {
+		s3.AccessKey = os.Getenv("AWS_ACCESS_KEY_ID")
+	}
+	if s3.SecretKey == "" {
+		s3.SecretKey = os.Getenv("AWS_SECRET_ACCESS_KEY")
+	}
+
+	if s3.Bucket == "" {
+		return nil, fmt.Errorf("failed to get s3 bucket from options or BUCKET env")
+	}
+	if s3.Endpoint == "" {
+		// the endpoint of AWS S3 is derived from the region
+		if s3.Region == "" {
+			return nil, fmt.Errorf("failed to get s3 region from options or REGION env")
+		}
+		s3.Endpoint = "s3." + s3.Region + ".amazonaws.com"
+	}
+	if s3.AccessKey == "" {
+		return nil, fmt.Errorf("failed to get aws access key from options or AWS_ACCESS_KEY_ID env")
+	}
+	if s3.SecretKey == "" {
+		return nil, fmt.Errorf("failed to get aws secret key from options or AWS_SECRET_ACCESS_KEY env")
+	}
+	return s3, nil
+}
+
+// CreateObjSecret creates the object storage secret referenced by the MCO CR, together with the
+// secret of the CA certificate of the S3 compatible endpoint if it is configured
+func CreateObjSecret(opt TestOptions) error {
+	config, caCert, err := GetObjStorageConfig(opt)
+	if err != nil {
+		return err
+	}
+
+	clientKube := getKubeClient(opt, true)
+	secrets := []*corev1.Secret{{
+		ObjectMeta: metav1.ObjectMeta{Name: OBJ_SECRET_NAME, Namespace: MCO_NAMESPACE},
+		Data:       map[string][]byte{OBJ_SECRET_KEY: config},
+		Type:       corev1.SecretTypeOpaque,
+	}}
+	if caCert != nil {
+		secrets = append(secrets, &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: OBJ_TLS_SECRET_NAME, Namespace: MCO_NAMESPACE},
+			Data:       map[string][]byte{"ca.crt": caCert},
+			Type:       corev1.SecretTypeOpaque,
+		})
+	}
+	klog.V(1).Infof("Create MCO object storage secret for %s", GetObjStorageProvider(opt))
+	for _, secret := range secrets {
+		found, err := clientKube.CoreV1().Secrets(MCO_NAMESPACE).Get(secret.Name, metav1.GetOptions{})
+		if err != nil && errors.IsNotFound(err) {
+			_, err = clientKube.CoreV1().Secrets(MCO_NAMESPACE).Create(secret)
+		} else if err == nil {
+			secret.ResourceVersion = found.ResourceVersion
+			_, err = clientKube.CoreV1().Secrets(MCO_NAMESPACE).Update(secret)
+		}
+		if err != nil {
+			klog.Errorf("Failed to create secret %s due to %v", secret.Name, err)
+			return err
+		}
+	}
+	return nil
+}
+
+// ModifyMCOObjStorageTLS sets the secret of the CA certificate created by CreateObjSecret in the
+// storageConfig of the MCO CR, so that it is mounted into the thanos components at the path of ca_file,
+// nothing is changed if the CA certificate is not configured
+func ModifyMCOObjStorageTLS(opt TestOptions) error {
+	if !hasObjStorageCACert(opt) {
+		return nil
+	}
+	clientDynamic := GetKubeClientDynamic(opt, true)
+	mco, getErr := clientDynamic.Resource(NewMCOGVRV1BETA2()).Get(MCO_CR_NAME, metav1.GetOptions{})
+	if getErr != nil {
+		return getErr
+	}
+
+	objectStorage := []string{"spec", "storageConfig", "metricObjectStorage"}
+	if err := unstructured.SetNestedField(mco.Object, OBJ_TLS_SECRET_NAME, append(objectStorage, "tlsSecretName")...); err != nil {
+		return err
+	}
+	if err := unstructured.SetNestedField(mco.Object, OBJ_TLS_SECRET_MOUNT_PATH, append(objectStorage, "tlsSecretMountPath")...); err != nil {
+		return err
+	}
+	_, updateErr := clientDynamic.Resource(NewMCOGVRV1BETA2()).Update(mco, metav1.UpdateOptions{})
+	if updateErr != nil {
+		return updateErr
+	}
+	return nil
+}
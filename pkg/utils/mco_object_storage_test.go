@@ -0,0 +1,88 @@
+// Copyright (c) 2021 Red Hat, Inc.
+// Copyright Contributors to the Open Cluster Management project
+
+package utils
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ghodss/yaml"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetObjStorageConfig(t *testing.T) {
+	for _, env := range []string{"BUCKET", "REGION", "AWS_ACCESS_KEY_ID", "AWS_SECRET_ACCESS_KEY"} {
+		if value, ok := os.LookupEnv(env); ok {
+			defer os.Setenv(env, value)
+			os.Unsetenv(env)
+		}
+	}
+
+	dir, err := ioutil.TempDir("", "obj-storage")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	caFile := filepath.Join(dir, "ca.crt")
+	require.NoError(t, ioutil.WriteFile(caFile, []byte("ca"), 0600))
+
+	opt := TestOptions{}
+	opt.Connection.Keys.AWS = AWSAPIKey{
+		AWSAccessID:     "id",
+		AWSAccessSecret: "secret",
+		Bucket:          "observability",
+		Endpoint:        "minio.example.com:9000",
+		Insecure:        true,
+		CACert:          caFile,
+	}
+	data, caCert, err := GetObjStorageConfig(opt)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("ca"), caCert)
+	config := map[string]interface{}{}
+	require.NoError(t, yaml.Unmarshal(data, &config))
+	assert.Equal(t, map[string]interface{}{
+		"type": "s3",
+		"config": map[string]interface{}{
+			"bucket":     "observability",
+			"endpoint":   "minio.example.com:9000",
+			"insecure":   true,
+			"access_key": "id",
+			"secret_key": "secret",
+			"http_config": map[string]interface{}{
+				"tls_config": map[string]interface{}{"ca_file": OBJ_TLS_SECRET_MOUNT_PATH + "/ca.crt"},
+			},
+		},
+	}, config)
+
+	// the endpoint of AWS S3 is derived from the region
+	opt.Connection.Keys.AWS = AWSAPIKey{AWSAccessID: "id", AWSAccessSecret: "secret", Bucket: "observability", Region: "us-east-1"}
+	data, caCert, err = GetObjStorageConfig(opt)
+	require.NoError(t, err)
+	assert.Nil(t, caCert)
+	assert.Contains(t, string(data), "endpoint: s3.us-east-1.amazonaws.com")
+
+	opt.Connection.Keys.AWS.CACert = filepath.Join(dir, "missing.crt")
+	_, _, err = GetObjStorageConfig(opt)
+	assert.Contains(t, err.Error(), "failed to read s3 CA certificate")
+
+	opt.Connection.Keys.AWS = AWSAPIKey{Region: "us-east-1"}
+	_, _, err = GetObjStorageConfig(opt)
+	assert.EqualError(t, err, "failed to get s3 bucket from options or BUCKET env")
+
+	opt.Connection.Keys.AWS = AWSAPIKey{}
+	opt.Connection.Keys.Azure = AzureAPIKey{StorageAccount: "account", StorageAccountKey: "key", Container: "observability"}
+	data, _, err = GetObjStorageConfig(opt)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "type: azure")
+	assert.Contains(t, string(data), "endpoint: "+defaultAzureEndpoint)
+
+	opt.Connection.ObjectStorageProvider = ObjStorageProviderGCP
+	_, _, err = GetObjStorageConfig(opt)
+	assert.EqualError(t, err, "failed to get gcs bucket or service account key from options")
+
+	opt.Connection.ObjectStorageProvider = "swift"
+	_, _, err = GetObjStorageConfig(opt)
+	assert.EqualError(t, err, "unsupported object storage provider swift")
+}
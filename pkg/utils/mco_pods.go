@@ -6,9 +6,11 @@ package utils
 import (
 	"bytes"
 	"io"
+	"time"
 
 	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
 	"k8s.io/klog"
 )
 
@@ -39,12 +41,23 @@ func DeletePod(opt TestOptions, isHub bool, namespace, name string) error {
 	return nil
 }
 
-func GetPodLogs(opt TestOptions, isHub bool, namespace, podName, containerName string, previous bool, tailLines int64) (string, error) {
-	clientKube := getKubeClient(opt, isHub)
+// GetPodLogs returns the logs of the container, the logs before sinceTime are skipped if it is not zero
+func GetPodLogs(opt TestOptions, isHub bool, namespace, podName, containerName string, previous bool, tailLines int64, sinceTime time.Time) (string, error) {
+	return getPodLogs(getKubeClient(opt, isHub), namespace, podName, containerName, previous, tailLines, sinceTime)
+}
+
+func getPodLogs(clientKube kubernetes.Interface, namespace, podName, containerName string, previous bool, tailLines int64, sinceTime time.Time) (string, error) {
 	podLogOpts := v1.PodLogOptions{
 		Container: containerName,
 		Previous:  previous,
-		TailLines: &tailLines,
+	}
+	if tailLines > 0 {
+		podLogOpts.TailLines = &tailLines
+	}
+	if !sinceTime.IsZero() {
+		// the timestamps are needed to tell the time of each line
+		podLogOpts.SinceTime = &metav1.Time{Time: sinceTime}
+		podLogOpts.Timestamps = true
 	}
 	req := clientKube.CoreV1().Pods(namespace).GetLogs(podName, &podLogOpts)
 	podLogs, err := req.Stream()
@@ -0,0 +1,73 @@
+// Copyright (c) 2021 Red Hat, Inc.
+// Copyright Contributors to the Open Cluster Management project
+
+package utils
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"time"
+
+	"k8s.io/client-go/tools/portforward"
+	"k8s.io/client-go/transport/spdy"
+	"k8s.io/klog"
+)
+
+// PortForward forwards a free local port to the port of the pod on the hub. It returns the local
+// port and the function to stop forwarding.
+func PortForward(opt TestOptions, namespace, podName string, port int) (int, func(), error) {
+	config, err := LoadConfig(opt.HubCluster.MasterURL, opt.KubeConfig, opt.HubCluster.KubeContext)
+	if err != nil {
+		return 0, nil, err
+	}
+	roundTripper, upgrader, err := spdy.RoundTripperFor(config)
+	if err != nil {
+		return 0, nil, err
+	}
+	serverURL, err := url.Parse(config.Host)
+	if err != nil {
+		return 0, nil, err
+	}
+	serverURL.Path = fmt.Sprintf("/api/v1/namespaces/%s/pods/%s/portforward", namespace, podName)
+	dialer := spdy.NewDialer(upgrader, &http.Client{Transport: roundTripper}, http.MethodPost, serverURL)
+
+	localPort, err := getFreePort()
+	if err != nil {
+		return 0, nil, err
+	}
+	stopCh := make(chan struct{})
+	readyCh := make(chan struct{})
+	forwarder, err := portforward.New(dialer, []string{fmt.Sprintf("%d:%d", localPort, port)}, stopCh, readyCh, ioutil.Discard, os.Stderr)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- forwarder.ForwardPorts()
+	}()
+	select {
+	case <-readyCh:
+	case err := <-errCh:
+		klog.Errorf("Failed to forward port %d of pod %s due to %v", port, podName, err)
+		return 0, nil, err
+	case <-time.After(time.Minute):
+		close(stopCh)
+		return 0, nil, fmt.Errorf("timeout to forward port %d of pod %s", port, podName)
+	}
+	klog.V(1).Infof("Forwarding 127.0.0.1:%d to port %d of pod %s", localPort, port, podName)
+	return localPort, func() { close(stopCh) }, nil
+}
+
+func getFreePort() (int, error) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return 0, err
+	}
+	defer listener.Close()
+	return listener.Addr().(*net.TCPAddr).Port, nil
+}
@@ -0,0 +1,186 @@
+// Copyright (c) 2021 Red Hat, Inc.
+// Copyright Contributors to the Open Cluster Management project
+
+package utils
+
+import (
+	"bytes"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io/ioutil"
+	"math"
+	"net/http"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/ghodss/yaml"
+	"github.com/golang/snappy"
+	"google.golang.org/protobuf/encoding/protowire"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog"
+)
+
+const (
+	ManagedClusterCACerts     = "observability-managed-cluster-certs"
+	ManagedClusterClientCerts = "observability-controller-open-cluster-management.io-observability-signer-client-cert"
+	ObservatoriumAPIWritePath = "/api/metrics/v1/default/api/v1/receive"
+	HubInfoSecretName         = "hub-info-secret"
+	HubInfoSecretKey          = "hub-info.yaml"
+	// the label of the synthetic series pushed by the e2e with the id of the run
+	SyntheticRunLabel = "e2e_run"
+	// the timeout of a remote write request
+	remoteWriteTimeout = 30 * time.Second
+)
+
+// MetricTimeSeries is a synthetic series to be pushed through the remote write API
+type MetricTimeSeries struct {
+	Labels  map[string]string
+	Samples []MetricSample
+}
+
+// EncodeRemoteWriteRequest encodes the series as a snappy compressed prometheus remote write request
+func EncodeRemoteWriteRequest(series []MetricTimeSeries) ([]byte, error) {
+	var req []byte
+	for _, ts := range series {
+		if _, ok := ts.Labels["__name__"]; !ok {
+			return nil, fmt.Errorf("the series %v has no __name__ label", ts.Labels)
+		}
+		var tsBuf []byte
+		// labels must be sorted by name
+		names := make([]string, 0, len(ts.Labels))
+		for name := range ts.Labels {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			var labelBuf []byte
+			labelBuf = protowire.AppendTag(labelBuf, 1, protowire.BytesType)
+			labelBuf = protowire.AppendString(labelBuf, name)
+			labelBuf = protowire.AppendTag(labelBuf, 2, protowire.BytesType)
+			labelBuf = protowire.AppendString(labelBuf, ts.Labels[name])
+			tsBuf = protowire.AppendTag(tsBuf, 1, protowire.BytesType)
+			tsBuf = protowire.AppendBytes(tsBuf, labelBuf)
+		}
+		for _, sample := range ts.Samples {
+			var sampleBuf []byte
+			sampleBuf = protowire.AppendTag(sampleBuf, 1, protowire.Fixed64Type)
+			sampleBuf = protowire.AppendFixed64(sampleBuf, math.Float64bits(sample.Value))
+			sampleBuf = protowire.AppendTag(sampleBuf, 2, protowire.VarintType)
+			sampleBuf = protowire.AppendVarint(sampleBuf, uint64(sample.Timestamp.UnixNano()/1e6))
+			tsBuf = protowire.AppendTag(tsBuf, 2, protowire.BytesType)
+			tsBuf = protowire.AppendBytes(tsBuf, sampleBuf)
+		}
+		req = protowire.AppendTag(req, 1, protowire.BytesType)
+		req = protowire.AppendBytes(req, tsBuf)
+	}
+	return snappy.Encode(nil, req), nil
+}
+
+// GetObservatoriumAPIURL returns the external URL of observatorium-api on the hub
+func GetObservatoriumAPIURL(opt TestOptions) string {
+	if os.Getenv("OBSERVATORIUM_API_URL") != "" {
+		return os.Getenv("OBSERVATORIUM_API_URL")
+	}
+	return "https://observatorium-api-" + MCO_NAMESPACE + ".apps." + opt.HubCluster.BaseDomain
+}
+
+// GetObservatoriumAPIWriteURL returns the write endpoint distributed to the managed cluster in the hub info secret,
+// or the default one when it is not found
+func GetObservatoriumAPIWriteURL(opt TestOptions) string {
+	clientKube := getKubeClient(opt, false)
+	secret, err := clientKube.CoreV1().Secrets(MCO_ADDON_NAMESPACE).Get(HubInfoSecretName, metav1.GetOptions{})
+	if err == nil {
+		hubInfo := struct {
+			Endpoint                 string `json:"endpoint,omitempty"`
+			ObservatoriumAPIEndpoint string `json:"observatorium-api-endpoint,omitempty"`
+		}{}
+		if err := yaml.Unmarshal(secret.Data[HubInfoSecretKey], &hubInfo); err == nil {
+			if hubInfo.ObservatoriumAPIEndpoint != "" {
+				return hubInfo.ObservatoriumAPIEndpoint
+			}
+			if hubInfo.Endpoint != "" {
+				return hubInfo.Endpoint
+			}
+		}
+	}
+	klog.V(1).Infof("Failed to discover the write endpoint from %s secret, using the default one", HubInfoSecretName)
+	return GetObservatoriumAPIURL(opt) + ObservatoriumAPIWritePath
+}
+
+// GetManagedClusterTLSConfig builds the TLS config used by metrics-collector from the certificates on the managed cluster
+func GetManagedClusterTLSConfig(opt TestOptions) (*tls.Config, error) {
+	clientKube := getKubeClient(opt, false)
+	caSecret, err := clientKube.CoreV1().Secrets(MCO_ADDON_NAMESPACE).Get(ManagedClusterCACerts, metav1.GetOptions{})
+	if err != nil {
+		klog.Errorf("Failed to get certificate secret %s due to %v", ManagedClusterCACerts, err)
+		return nil, err
+	}
+	clientSecret, err := clientKube.CoreV1().Secrets(MCO_ADDON_NAMESPACE).Get(ManagedClusterClientCerts, metav1.GetOptions{})
+	if err != nil {
+		klog.Errorf("Failed to get certificate secret %s due to %v", ManagedClusterClientCerts, err)
+		return nil, err
+	}
+
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(caSecret.Data["ca.crt"]) {
+		return nil, fmt.Errorf("failed to get ca.crt from %s secret", ManagedClusterCACerts)
+	}
+	cert, err := tls.X509KeyPair(clientSecret.Data["tls.crt"], clientSecret.Data["tls.key"])
+	if err != nil {
+		return nil, fmt.Errorf("failed to load client certificate from %s secret: %v", ManagedClusterClientCerts, err)
+	}
+	return &tls.Config{
+		RootCAs:      pool,
+		Certificates: []tls.Certificate{cert},
+	}, nil
+}
+
+// RemoteWriteMetrics pushes the series to the observatorium-api write endpoint of metrics-collector using
+// the managed cluster client certificate
+func RemoteWriteMetrics(opt TestOptions, series []MetricTimeSeries) error {
+	tlsConfig, err := GetManagedClusterTLSConfig(opt)
+	if err != nil {
+		return err
+	}
+	return remoteWrite(GetObservatoriumAPIWriteURL(opt), tlsConfig, series)
+}
+
+// RemoteWriteMetricsToReceive pushes the series to thanos-receive directly, bypassing observatorium-api
+func RemoteWriteMetricsToReceive(receiveURL string, series []MetricTimeSeries) error {
+	return remoteWrite(receiveURL, &tls.Config{InsecureSkipVerify: true}, series)
+}
+
+func remoteWrite(writeURL string, tlsConfig *tls.Config, series []MetricTimeSeries) error {
+	body, err := EncodeRemoteWriteRequest(series)
+	if err != nil {
+		return err
+	}
+
+	klog.V(5).Infof("remote write url is: %s\n", writeURL)
+	req, err := http.NewRequest("POST", writeURL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Encoding", "snappy")
+	req.Header.Set("Content-Type", "application/x-protobuf")
+	req.Header.Set("X-Prometheus-Remote-Write-Version", "0.1.0")
+
+	client := &http.Client{
+		Transport: &http.Transport{TLSClientConfig: tlsConfig},
+		Timeout:   remoteWriteTimeout,
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode/100 != 2 {
+		result, _ := ioutil.ReadAll(resp.Body)
+		klog.Errorf("resp.StatusCode: %v\n", resp.StatusCode)
+		return fmt.Errorf("failed to remote write metrics with status %d: %s", resp.StatusCode, result)
+	}
+	return nil
+}
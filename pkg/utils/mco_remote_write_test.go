@@ -0,0 +1,87 @@
+// Copyright (c) 2021 Red Hat, Inc.
+// Copyright Contributors to the Open Cluster Management project
+
+package utils
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/golang/snappy"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+func TestEncodeRemoteWriteRequest(t *testing.T) {
+	ts := time.Unix(1600000000, 123*int64(time.Millisecond))
+	buf, err := EncodeRemoteWriteRequest([]MetricTimeSeries{
+		{
+			Labels: map[string]string{
+				"cluster":  "cluster1",
+				"__name__": "e2e_synthetic_metric",
+			},
+			Samples: []MetricSample{{Timestamp: ts, Value: 42.5}},
+		},
+	})
+	require.NoError(t, err, "EncodeRemoteWriteRequest()")
+
+	req, err := snappy.Decode(nil, buf)
+	require.NoError(t, err, "snappy.Decode()")
+
+	series := consumeMessages(t, req, 1)
+	require.Len(t, series, 1)
+	fields := consumeFields(t, series[0])
+
+	labels := []string{}
+	for _, l := range fields[1] {
+		label := consumeFields(t, l.([]byte))
+		labels = append(labels, string(label[1][0].([]byte))+"="+string(label[2][0].([]byte)))
+	}
+	assert.Equal(t, []string{"__name__=e2e_synthetic_metric", "cluster=cluster1"}, labels, "sorted labels")
+
+	require.Len(t, fields[2], 1)
+	sample := consumeFields(t, fields[2][0].([]byte))
+	assert.Equal(t, 42.5, math.Float64frombits(sample[1][0].(uint64)), "sample value")
+	assert.Equal(t, uint64(1600000000123), sample[2][0].(uint64), "sample timestamp")
+
+	_, err = EncodeRemoteWriteRequest([]MetricTimeSeries{{Labels: map[string]string{"cluster": "cluster1"}}})
+	assert.Error(t, err, "series without __name__ should be rejected")
+}
+
+func consumeMessages(t *testing.T, b []byte, num protowire.Number) (msgs [][]byte) {
+	for _, v := range consumeFields(t, b)[num] {
+		msgs = append(msgs, v.([]byte))
+	}
+	return
+}
+
+func consumeFields(t *testing.T, b []byte) map[protowire.Number][]interface{} {
+	fields := map[protowire.Number][]interface{}{}
+	for len(b) > 0 {
+		num, typ, n := protowire.ConsumeTag(b)
+		require.True(t, n > 0, "ConsumeTag()")
+		b = b[n:]
+		switch typ {
+		case protowire.BytesType:
+			v, n := protowire.ConsumeBytes(b)
+			require.True(t, n > 0, "ConsumeBytes()")
+			fields[num] = append(fields[num], v)
+			b = b[n:]
+		case protowire.Fixed64Type:
+			v, n := protowire.ConsumeFixed64(b)
+			require.True(t, n > 0, "ConsumeFixed64()")
+			fields[num] = append(fields[num], v)
+			b = b[n:]
+		case protowire.VarintType:
+			v, n := protowire.ConsumeVarint(b)
+			require.True(t, n > 0, "ConsumeVarint()")
+			fields[num] = append(fields[num], v)
+			b = b[n:]
+		default:
+			t.Fatalf("unexpected wire type %v", typ)
+		}
+	}
+	return fields
+}
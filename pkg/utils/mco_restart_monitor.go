@@ -0,0 +1,282 @@
+// Copyright (c) 2021 Red Hat, Inc.
+// Copyright Contributors to the Open Cluster Management project
+
+package utils
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/klog"
+)
+
+const (
+	ReasonCrashLoopBackOff = "CrashLoopBackOff"
+	ReasonOOMKilled        = "OOMKilled"
+
+	// the lines of the previous container logs attached to a restart
+	restartLogTailLines = int64(100)
+)
+
+// RestartEvent is a container restart or crash loop observed by the RestartMonitor
+type RestartEvent struct {
+	Time         time.Time
+	Cluster      string
+	Namespace    string
+	Pod          string
+	Container    string
+	Reason       string
+	RestartCount int32
+	Labels       map[string]string
+	// Logs is the tail of the logs of the previous container
+	Logs string
+}
+
+func (e RestartEvent) String() string {
+	return fmt.Sprintf("%s %s/%s/%s container %s restarted %d times (%s)", e.Time.UTC().Format(time.RFC3339),
+		e.Cluster, e.Namespace, e.Pod, e.Container, e.RestartCount, e.Reason)
+}
+
+// expectedRestart matches the restarts declared by the specs
+type expectedRestart struct {
+	cluster   string
+	namespace string
+	selector  labels.Selector
+}
+
+func (r expectedRestart) matches(e RestartEvent) bool {
+	return (r.cluster == "" || r.cluster == e.Cluster) &&
+		(r.namespace == "" || r.namespace == e.Namespace) &&
+		r.selector.Matches(labels.Set(e.Labels))
+}
+
+// RestartMonitor watches the pods in MCO_NAMESPACE and MCO_ADDON_NAMESPACE on the hub and all managed
+// clusters, and records the container restarts, OOMKills and crash loops
+type RestartMonitor struct {
+	clients map[string]kubernetes.Interface
+
+	mu       sync.Mutex
+	logs     sync.WaitGroup
+	events   []*RestartEvent
+	expected []expectedRestart
+	// the last seen restart count and waiting reason of each container
+	restarts  map[string]int32
+	waiting   map[string]string
+	startedAt time.Time
+	stopCh    chan struct{}
+}
+
+// NewRestartMonitor returns a monitor for the hub and the managed clusters in the options
+func NewRestartMonitor(opt TestOptions) *RestartMonitor {
+	return &RestartMonitor{
+		clients:  getClusterClients(opt),
+		restarts: map[string]int32{},
+		waiting:  map[string]string{},
+	}
+}
+
+// Start starts watching the pods, the restarts happened before are ignored
+func (m *RestartMonitor) Start() error {
+	m.stopCh = make(chan struct{})
+	m.startedAt = time.Now()
+	for cluster, client := range m.clients {
+		for _, ns := range []string{MCO_NAMESPACE, MCO_ADDON_NAMESPACE} {
+			factory := informers.NewSharedInformerFactoryWithOptions(client, 0, informers.WithNamespace(ns))
+			informer := factory.Core().V1().Pods().Informer()
+			cluster, client := cluster, client
+			informer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+				AddFunc: func(obj interface{}) {
+					pod := obj.(*corev1.Pod)
+					m.observe(cluster, client, pod, pod.CreationTimestamp.Time.Before(m.startedAt))
+				},
+				UpdateFunc: func(_, obj interface{}) {
+					m.observe(cluster, client, obj.(*corev1.Pod), false)
+				},
+				DeleteFunc: func(obj interface{}) {
+					if pod, ok := obj.(*corev1.Pod); ok {
+						m.forget(cluster, pod)
+					}
+				},
+			})
+			factory.Start(m.stopCh)
+			if !cache.WaitForCacheSync(m.stopCh, informer.HasSynced) {
+				m.Stop()
+				return fmt.Errorf("failed to sync the pods in namespace %s of cluster %s", ns, cluster)
+			}
+		}
+	}
+	klog.V(1).Infof("Started the restart monitor on clusters %v", m.clusterNames())
+	return nil
+}
+
+// Stop stops watching the pods
+func (m *RestartMonitor) Stop() {
+	if m.stopCh != nil {
+		close(m.stopCh)
+		m.stopCh = nil
+	}
+}
+
+// ExpectRestarts declares the restarts of the pods matching the label selector are expected until the
+// next Reset, empty cluster or namespace matches all
+func (m *RestartMonitor) ExpectRestarts(cluster, namespace, labelSelector string) error {
+	selector, err := labels.Parse(labelSelector)
+	if err != nil {
+		return err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.expected = append(m.expected, expectedRestart{cluster: cluster, namespace: namespace, selector: selector})
+	return nil
+}
+
+// ExpectComponentRestarts declares the restarts of the components in the registry are expected
+func (m *RestartMonitor) ExpectComponentRestarts(names ...string) error {
+	for _, name := range names {
+		c, err := GetComponent(name)
+		if err != nil {
+			return err
+		}
+		if err := m.ExpectRestarts("", c.Namespace(), c.Label); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Reset clears the recorded restarts and the expected restarts
+func (m *RestartMonitor) Reset() {
+	m.logs.Wait()
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.events = nil
+	m.expected = nil
+}
+
+// Events returns the restarts recorded since the last Reset
+func (m *RestartMonitor) Events() []RestartEvent {
+	m.logs.Wait()
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	events := []RestartEvent{}
+	for _, e := range m.events {
+		events = append(events, *e)
+	}
+	return events
+}
+
+// Unexpected returns the restarts recorded since the last Reset which are not expected
+func (m *RestartMonitor) Unexpected() []RestartEvent {
+	events := m.Events()
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	unexpected := []RestartEvent{}
+	for _, e := range events {
+		matched := false
+		for _, r := range m.expected {
+			if r.matches(e) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			unexpected = append(unexpected, e)
+		}
+	}
+	return unexpected
+}
+
+// Check returns an error with the logs of the previous containers if any restart is unexpected
+func (m *RestartMonitor) Check() error {
+	unexpected := m.Unexpected()
+	if len(unexpected) == 0 {
+		return nil
+	}
+	msgs := []string{}
+	for _, e := range unexpected {
+		msgs = append(msgs, fmt.Sprintf("%s\n--- logs of the previous container ---\n%s", e, e.Logs))
+	}
+	return fmt.Errorf("found %d unexpected restarts:\n%s", len(unexpected), strings.Join(msgs, "\n"))
+}
+
+func (m *RestartMonitor) clusterNames() []string {
+	names := []string{}
+	for name := range m.clients {
+		names = append(names, name)
+	}
+	return names
+}
+
+// observe records the restarts of the containers in the pod, baseline only records the current state
+// of the pods existing before the monitor is started
+func (m *RestartMonitor) observe(cluster string, client kubernetes.Interface, pod *corev1.Pod, baseline bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, status := range containerStatuses(*pod) {
+		key := strings.Join([]string{cluster, pod.Namespace, pod.Name, status.Name}, "/")
+		waitingReason := ""
+		if status.State.Waiting != nil {
+			waitingReason = status.State.Waiting.Reason
+		}
+		lastRestarts, lastWaiting := m.restarts[key], m.waiting[key]
+		m.restarts[key], m.waiting[key] = status.RestartCount, waitingReason
+		if baseline {
+			continue
+		}
+
+		event := &RestartEvent{
+			Time:         time.Now(),
+			Cluster:      cluster,
+			Namespace:    pod.Namespace,
+			Pod:          pod.Name,
+			Container:    status.Name,
+			RestartCount: status.RestartCount,
+			Labels:       pod.Labels,
+		}
+		switch {
+		case status.RestartCount > lastRestarts:
+			event.Reason = "Restarted"
+			if terminated := status.LastTerminationState.Terminated; terminated != nil {
+				event.Reason = terminated.Reason
+				event.Time = terminated.FinishedAt.Time
+			}
+		case waitingReason == ReasonCrashLoopBackOff && lastWaiting != ReasonCrashLoopBackOff:
+			event.Reason = ReasonCrashLoopBackOff
+		default:
+			continue
+		}
+		klog.V(1).Infof("Observed container restart: %s", event)
+		m.events = append(m.events, event)
+
+		m.logs.Add(1)
+		go func(event *RestartEvent) {
+			defer m.logs.Done()
+			logs, err := getPodLogs(client, event.Namespace, event.Pod, event.Container, true, restartLogTailLines, time.Time{})
+			if err != nil {
+				logs = fmt.Sprintf("failed to get the logs: %v", err)
+			}
+			m.mu.Lock()
+			defer m.mu.Unlock()
+			event.Logs = logs
+		}(event)
+	}
+}
+
+func (m *RestartMonitor) forget(cluster string, pod *corev1.Pod) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	prefix := strings.Join([]string{cluster, pod.Namespace, pod.Name}, "/") + "/"
+	for key := range m.restarts {
+		if strings.HasPrefix(key, prefix) {
+			delete(m.restarts, key)
+			delete(m.waiting, key)
+		}
+	}
+}
@@ -0,0 +1,225 @@
+// Copyright (c) 2021 Red Hat, Inc.
+// Copyright Contributors to the Open Cluster Management project
+
+package utils
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"k8s.io/klog"
+)
+
+// RetentionConfig is the retention of each resolution applied by thanos-compact, 0 means forever
+type RetentionConfig struct {
+	Raw         time.Duration
+	FiveMinutes time.Duration
+	OneHour     time.Duration
+	// IgnoreDeletionMarksDelay is the delay of thanos-store to stop serving the blocks marked for deletion
+	IgnoreDeletionMarksDelay time.Duration
+}
+
+// For returns the retention of the resolution in milliseconds
+func (r RetentionConfig) For(resolution int64) time.Duration {
+	switch resolution {
+	case Resolution5m:
+		return r.FiveMinutes
+	case Resolution1h:
+		return r.OneHour
+	default:
+		return r.Raw
+	}
+}
+
+// GetRetentionConfig returns the retention from the args of thanos-compact
+func GetRetentionConfig(opt TestOptions) (RetentionConfig, error) {
+	config := RetentionConfig{}
+	compacts, err := GetStatefulSetWithLabel(opt, true, ThanosCompactLabel, MCO_NAMESPACE)
+	if err != nil {
+		return config, err
+	}
+	if len(compacts.Items) == 0 {
+		return config, fmt.Errorf("no thanos-compact statefulset found")
+	}
+	args := map[string]*time.Duration{
+		"--retention.resolution-raw=": &config.Raw,
+		"--retention.resolution-5m=":  &config.FiveMinutes,
+		"--retention.resolution-1h=":  &config.OneHour,
+	}
+	if err := parseDurationArgs(compacts.Items[0].Spec.Template.Spec.Containers[0].Args, args); err != nil {
+		return config, err
+	}
+
+	stores, err := GetStatefulSetWithLabel(opt, true, ThanosStoreLabel, MCO_NAMESPACE)
+	if err != nil {
+		return config, err
+	}
+	if len(stores.Items) == 0 {
+		return config, fmt.Errorf("no thanos-store statefulset found")
+	}
+	err = parseDurationArgs(stores.Items[0].Spec.Template.Spec.Containers[0].Args, map[string]*time.Duration{
+		"--ignore-deletion-marks-delay=": &config.IgnoreDeletionMarksDelay,
+	})
+	return config, err
+}
+
+func parseDurationArgs(argList []string, args map[string]*time.Duration) error {
+	for _, arg := range argList {
+		for prefix, d := range args {
+			if !strings.HasPrefix(arg, prefix) {
+				continue
+			}
+			var err error
+			if *d, err = ParsePromDuration(strings.TrimPrefix(arg, prefix)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// ExpiredBlocks returns the blocks of the resolution with all their data older than the retention of the
+// resolution, which thanos-compact should mark for deletion
+func ExpiredBlocks(blocks []BlockMeta, resolution int64, now time.Time, retention RetentionConfig) []BlockMeta {
+	expired := []BlockMeta{}
+	r := retention.For(resolution)
+	if r == 0 {
+		return expired
+	}
+	for _, b := range blocks {
+		if b.Thanos.Downsample.Resolution == resolution && now.Sub(msToTime(b.MaxTime)) > r+CompactionInterval {
+			expired = append(expired, b)
+		}
+	}
+	return expired
+}
+
+// GoneDataTimes returns the middle times of the expired blocks of the resolution which should not be
+// queryable anymore with the max source resolution and the lookback of the query: the blocks are marked for
+// deletion for longer than the delay of thanos-store, and no other block of the same or a finer resolution
+// served by thanos-store covers the lookback window
+func GoneDataTimes(blocks []BlockMeta, resolution int64, lookback time.Duration, now time.Time, retention RetentionConfig) []time.Time {
+	served := func(b BlockMeta) bool {
+		return !b.MarkedForDeletion || now.Sub(b.DeletionTime) <= retention.IgnoreDeletionMarksDelay
+	}
+	times := []time.Time{}
+	for _, expired := range ExpiredBlocks(blocks, resolution, now, retention) {
+		if served(expired) {
+			continue
+		}
+		ts := (expired.MinTime + expired.MaxTime) / 2
+		covered := false
+		for _, b := range blocks {
+			if b.Thanos.Downsample.Resolution <= resolution && b.MinTime <= ts &&
+				b.MaxTime > ts-lookback.Nanoseconds()/int64(time.Millisecond) && served(b) {
+				covered = true
+				break
+			}
+		}
+		if !covered {
+			times = append(times, msToTime(ts))
+		}
+	}
+	return times
+}
+
+func msToTime(ms int64) time.Time {
+	return time.Unix(0, ms*int64(time.Millisecond))
+}
+
+// UploadBlock uploads the block in the directory to the bucket with the external labels. The meta.json
+// is uploaded last so that the block is complete when it is found by the thanos components.
+func UploadBlock(c *S3Client, dir string, labels map[string]string) (*BlockMeta, error) {
+	ulid := filepath.Base(dir)
+	if !ulidRegexp.MatchString(ulid) {
+		return nil, fmt.Errorf("%s is not a block directory", dir)
+	}
+	metaData, err := ioutil.ReadFile(filepath.Join(dir, BlockMetaFile))
+	if err != nil {
+		return nil, err
+	}
+	// keep all the fields of meta.json, only the thanos section is updated
+	meta := map[string]interface{}{}
+	if err := json.Unmarshal(metaData, &meta); err != nil {
+		return nil, fmt.Errorf("failed to parse %s of block %s: %v", BlockMetaFile, ulid, err)
+	}
+	thanos, _ := meta["thanos"].(map[string]interface{})
+	if thanos == nil {
+		thanos = map[string]interface{}{}
+	}
+	extLabels, _ := thanos["labels"].(map[string]interface{})
+	if extLabels == nil {
+		extLabels = map[string]interface{}{}
+	}
+	for k, v := range labels {
+		extLabels[k] = v
+	}
+	thanos["labels"] = extLabels
+	if _, ok := thanos["downsample"]; !ok {
+		thanos["downsample"] = map[string]interface{}{"resolution": 0}
+	}
+	if source, _ := thanos["source"].(string); source == "" {
+		thanos["source"] = "e2e"
+	}
+	meta["thanos"] = thanos
+	if metaData, err = json.Marshal(meta); err != nil {
+		return nil, err
+	}
+
+	err = filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() || path == filepath.Join(dir, BlockMetaFile) {
+			return err
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		data, err := ioutil.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		return c.PutObject(ulid+"/"+filepath.ToSlash(rel), data)
+	})
+	if err != nil {
+		klog.Errorf("Failed to upload block %s due to %v", ulid, err)
+		return nil, err
+	}
+	if err := c.PutObject(ulid+"/"+BlockMetaFile, metaData); err != nil {
+		return nil, err
+	}
+
+	block := &BlockMeta{}
+	if err := json.Unmarshal(metaData, block); err != nil {
+		return nil, err
+	}
+	klog.V(1).Infof("Uploaded block %s", block)
+	return block, nil
+}
+
+// UploadBlocks uploads all blocks in the directory
+func UploadBlocks(c *S3Client, dir string, labels map[string]string) ([]BlockMeta, error) {
+	entries, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	blocks := []BlockMeta{}
+	for _, entry := range entries {
+		if !entry.IsDir() || !ulidRegexp.MatchString(entry.Name()) {
+			continue
+		}
+		block, err := UploadBlock(c, filepath.Join(dir, entry.Name()), labels)
+		if err != nil {
+			return nil, err
+		}
+		blocks = append(blocks, *block)
+	}
+	if len(blocks) == 0 {
+		return nil, fmt.Errorf("no block found in %s", dir)
+	}
+	return blocks, nil
+}
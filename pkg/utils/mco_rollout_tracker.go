@@ -0,0 +1,256 @@
+// Copyright (c) 2021 Red Hat, Inc.
+// Copyright Contributors to the Open Cluster Management project
+
+package utils
+
+import (
+	"fmt"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/klog"
+)
+
+// the annotation of the revision of a deployment, set by the deployment controller
+const deploymentRevisionAnnotation = "deployment.kubernetes.io/revision"
+
+// workloadSnapshot is the state of a workload when the tracker is created
+type workloadSnapshot struct {
+	Kind       string
+	Name       string
+	Namespace  string
+	Generation int64
+	Revision   string
+	Selector   string
+	Pods       []corev1.Pod
+}
+
+// RolloutTracker proves the workloads actually restarted: it snapshots the generation, the revision and
+// the pods of the workloads, then checks a new revision is rolled out, every old pod is replaced and the
+// new pods are ready. The status-only updates of the workloads are not restarts.
+type RolloutTracker struct {
+	client    kubernetes.Interface
+	cluster   string
+	snapshots []workloadSnapshot
+}
+
+// NewRolloutTracker snapshots the deployment or statefulset on the hub or the managed cluster
+func NewRolloutTracker(opt TestOptions, isHub bool, kind, name, namespace string) (*RolloutTracker, error) {
+	t := &RolloutTracker{client: getKubeClient(opt, isHub), cluster: clusterOf(isHub)}
+	snapshot, err := t.snapshot(kind, name, namespace)
+	if err != nil {
+		return nil, err
+	}
+	t.snapshots = append(t.snapshots, snapshot)
+	return t, nil
+}
+
+// NewComponentRolloutTracker snapshots all workloads of the MCO component, e.g. thanos-rule or
+// metrics-collector
+func NewComponentRolloutTracker(opt TestOptions, name string) (*RolloutTracker, error) {
+	c, err := GetComponent(name)
+	if err != nil {
+		return nil, err
+	}
+	workloads, err := c.GetWorkloads(opt)
+	if err != nil {
+		return nil, err
+	}
+	if len(workloads) == 0 {
+		return nil, fmt.Errorf("should have %s created with label %s", c.Kind, c.Label)
+	}
+	t := &RolloutTracker{client: getKubeClient(opt, c.Hub), cluster: clusterOf(c.Hub)}
+	for _, w := range workloads {
+		snapshot, err := t.snapshot(c.Kind, w.Name, c.Namespace())
+		if err != nil {
+			return nil, err
+		}
+		t.snapshots = append(t.snapshots, snapshot)
+	}
+	return t, nil
+}
+
+func clusterOf(isHub bool) string {
+	if isHub {
+		return HubClusterName
+	}
+	return "managed cluster"
+}
+
+// getWorkload returns the workload with its revision and pod selector
+func (t *RolloutTracker) getWorkload(kind, name, namespace string) (Workload, string, string, error) {
+	switch kind {
+	case KindDeployment:
+		d, err := t.client.AppsV1().Deployments(namespace).Get(name, metav1.GetOptions{})
+		if err != nil {
+			return Workload{}, "", "", err
+		}
+		selector, err := metav1.LabelSelectorAsSelector(d.Spec.Selector)
+		if err != nil {
+			return Workload{}, "", "", err
+		}
+		return deploymentWorkload(*d), d.Annotations[deploymentRevisionAnnotation], selector.String(), nil
+	case KindStatefulSet:
+		s, err := t.client.AppsV1().StatefulSets(namespace).Get(name, metav1.GetOptions{})
+		if err != nil {
+			return Workload{}, "", "", err
+		}
+		selector, err := metav1.LabelSelectorAsSelector(s.Spec.Selector)
+		if err != nil {
+			return Workload{}, "", "", err
+		}
+		return statefulSetWorkload(*s), s.Status.UpdateRevision, selector.String(), nil
+	}
+	return Workload{}, "", "", fmt.Errorf("unknown workload kind %s", kind)
+}
+
+func (t *RolloutTracker) snapshot(kind, name, namespace string) (workloadSnapshot, error) {
+	w, revision, selector, err := t.getWorkload(kind, name, namespace)
+	if err != nil {
+		return workloadSnapshot{}, err
+	}
+	pods, err := t.client.CoreV1().Pods(namespace).List(metav1.ListOptions{LabelSelector: selector})
+	if err != nil {
+		return workloadSnapshot{}, err
+	}
+	snapshot := workloadSnapshot{
+		Kind:       kind,
+		Name:       name,
+		Namespace:  namespace,
+		Generation: w.Generation,
+		Revision:   revision,
+		Selector:   selector,
+		Pods:       pods.Items,
+	}
+	klog.V(1).Infof("Tracking the rollout of %s %s/%s in %s from generation %d, revision %s and %d pods",
+		kind, namespace, name, t.cluster, w.Generation, revision, len(pods.Items))
+	return snapshot, nil
+}
+
+// checkRevision checks the workload rolled out a new revision
+func checkRevision(s workloadSnapshot, w Workload, revision string) error {
+	if revision == "" || revision == s.Revision {
+		return fmt.Errorf("%s %s should have a new revision but still has revision %s", s.Kind, s.Name, s.Revision)
+	}
+	return w.CheckRolledOut()
+}
+
+// Check checks a new revision of each workload is rolled out, all old pods are replaced and the new pods
+// are ready
+func (t *RolloutTracker) Check() error {
+	for _, s := range t.snapshots {
+		w, revision, _, err := t.getWorkload(s.Kind, s.Name, s.Namespace)
+		if err != nil {
+			return err
+		}
+		if err := checkRevision(s, w, revision); err != nil {
+			return err
+		}
+		pods, err := t.client.CoreV1().Pods(s.Namespace).List(metav1.ListOptions{LabelSelector: s.Selector})
+		if err != nil {
+			return err
+		}
+		if err := checkPodsReplaced(s, pods.Items, w.Replicas); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func checkPodsReplaced(s workloadSnapshot, pods []corev1.Pod, replicas int32) error {
+	old := map[string]bool{}
+	for _, pod := range s.Pods {
+		old[string(pod.UID)] = true
+	}
+	ready := int32(0)
+	for _, pod := range pods {
+		if old[string(pod.UID)] {
+			return fmt.Errorf("pod %s of %s %s is not replaced yet", pod.Name, s.Kind, s.Name)
+		}
+		if !IsPodReady(pod) {
+			return fmt.Errorf("new pod %s of %s %s is not ready yet", pod.Name, s.Kind, s.Name)
+		}
+		ready++
+	}
+	if ready != replicas {
+		return fmt.Errorf("%s %s should have %d but got %d new ready pods", s.Kind, s.Name, replicas, ready)
+	}
+	return nil
+}
+
+// Wait watches each workload until a new revision is rolled out and all old pods are replaced by ready
+// pods, the error on timeout lists the states observed while waiting
+func (t *RolloutTracker) Wait(timeout time.Duration) error {
+	return t.wait(timeout, true)
+}
+
+// WaitReplaced is Wait for a change which is reverted, e.g. a drift reverted by the operators: the
+// workload may be back to the revision of the snapshot, but all old pods are still replaced by ready pods
+func (t *RolloutTracker) WaitReplaced(timeout time.Duration) error {
+	return t.wait(timeout, false)
+}
+
+func (t *RolloutTracker) wait(timeout time.Duration, newRevision bool) error {
+	deadline := time.Now().Add(timeout)
+	for _, s := range t.snapshots {
+		s := s
+		lw, objType, err := workloadListWatch(t.client, s.Kind, s.Name, s.Namespace)
+		if err != nil {
+			return err
+		}
+		var replicas int32
+		what := fmt.Sprintf("%s %s/%s in %s to roll out", s.Kind, s.Namespace, s.Name, t.cluster)
+		if newRevision {
+			what += " a new revision"
+		}
+		err = waitForState(lw, objType, what, time.Until(deadline), func(store cache.Store) (bool, string) {
+			objs := store.List()
+			if len(objs) == 0 {
+				return false, "not found"
+			}
+			var (
+				w        Workload
+				revision string
+			)
+			switch obj := objs[0].(type) {
+			case *appsv1.Deployment:
+				w, revision = deploymentWorkload(*obj), obj.Annotations[deploymentRevisionAnnotation]
+			case *appsv1.StatefulSet:
+				w, revision = statefulSetWorkload(*obj), obj.Status.UpdateRevision
+			}
+			check := w.CheckRolledOut
+			if newRevision {
+				check = func() error { return checkRevision(s, w, revision) }
+			}
+			if err := check(); err != nil {
+				return false, err.Error()
+			}
+			replicas = w.Replicas
+			return true, "rolled out revision " + revision
+		})
+		if err != nil {
+			return err
+		}
+
+		lw = podListWatch(t.client, s.Namespace, s.Selector)
+		what = fmt.Sprintf("pods of %s %s/%s in %s to be replaced", s.Kind, s.Namespace, s.Name, t.cluster)
+		err = waitForState(lw, &corev1.Pod{}, what, time.Until(deadline), func(store cache.Store) (bool, string) {
+			pods := []corev1.Pod{}
+			for _, obj := range store.List() {
+				pods = append(pods, *obj.(*corev1.Pod))
+			}
+			if err := checkPodsReplaced(s, pods, replicas); err != nil {
+				return false, err.Error()
+			}
+			return true, "replaced"
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,117 @@
+// Copyright (c) 2021 Red Hat, Inc.
+// Copyright Contributors to the Open Cluster Management project
+
+package utils
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func newRulePod(uid string, ready bool) *corev1.Pod {
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "observability-thanos-rule-0",
+			Namespace: MCO_NAMESPACE,
+			UID:       types.UID(uid),
+			Labels:    map[string]string{"app.kubernetes.io/name": "thanos-rule"},
+		},
+		Status: corev1.PodStatus{
+			Phase:             corev1.PodRunning,
+			ContainerStatuses: []corev1.ContainerStatus{{Name: "thanos-rule", Ready: ready}},
+		},
+	}
+}
+
+func newRuleStatefulSet(revision string) *appsv1.StatefulSet {
+	replicas := int32(1)
+	return &appsv1.StatefulSet{
+		ObjectMeta: metav1.ObjectMeta{Name: "observability-thanos-rule", Namespace: MCO_NAMESPACE, Generation: 1},
+		Spec: appsv1.StatefulSetSpec{
+			Replicas: &replicas,
+			Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app.kubernetes.io/name": "thanos-rule"}},
+		},
+		Status: appsv1.StatefulSetStatus{
+			ObservedGeneration: 1,
+			Replicas:           1,
+			ReadyReplicas:      1,
+			UpdatedReplicas:    1,
+			CurrentRevision:    revision,
+			UpdateRevision:     revision,
+		},
+	}
+}
+
+func TestRolloutTracker(t *testing.T) {
+	client := fake.NewSimpleClientset(newRuleStatefulSet("rev-1"), newRulePod("old", true))
+	tracker := &RolloutTracker{client: client, cluster: HubClusterName}
+	snapshot, err := tracker.snapshot(KindStatefulSet, "observability-thanos-rule", MCO_NAMESPACE)
+	require.NoError(t, err)
+	require.Len(t, snapshot.Pods, 1)
+	assert.Equal(t, "rev-1", snapshot.Revision)
+	assert.Equal(t, "app.kubernetes.io/name=thanos-rule", snapshot.Selector)
+	tracker.snapshots = append(tracker.snapshots, snapshot)
+
+	// a status-only update is not a restart
+	sts := newRuleStatefulSet("rev-1")
+	_, err = client.AppsV1().StatefulSets(MCO_NAMESPACE).UpdateStatus(sts)
+	require.NoError(t, err)
+	assert.Contains(t, tracker.Check().Error(), "should have a new revision")
+	err = tracker.Wait(200 * time.Millisecond)
+	require.Error(t, err)
+	_, ok := err.(*WaitTimeoutError)
+	assert.True(t, ok)
+
+	// the new revision is rolled out but the old pod is not replaced yet
+	_, err = client.AppsV1().StatefulSets(MCO_NAMESPACE).UpdateStatus(newRuleStatefulSet("rev-2"))
+	require.NoError(t, err)
+	assert.Contains(t, tracker.Check().Error(), "is not replaced yet")
+
+	go func() {
+		time.Sleep(100 * time.Millisecond)
+		pods := client.CoreV1().Pods(MCO_NAMESPACE)
+		_ = pods.Delete("observability-thanos-rule-0", &metav1.DeleteOptions{})
+		_, _ = pods.Create(newRulePod("new", false))
+		time.Sleep(100 * time.Millisecond)
+		_, _ = pods.UpdateStatus(newRulePod("new", true))
+	}()
+	assert.NoError(t, tracker.Wait(10*time.Second))
+	assert.NoError(t, tracker.Check())
+}
+
+func TestRolloutTrackerWaitReplaced(t *testing.T) {
+	client := fake.NewSimpleClientset(newRuleStatefulSet("rev-1"), newRulePod("old", true))
+	tracker := &RolloutTracker{client: client, cluster: HubClusterName}
+	snapshot, err := tracker.snapshot(KindStatefulSet, "observability-thanos-rule", MCO_NAMESPACE)
+	require.NoError(t, err)
+	tracker.snapshots = append(tracker.snapshots, snapshot)
+
+	// the old pod is not replaced yet
+	assert.Error(t, tracker.WaitReplaced(200*time.Millisecond))
+
+	// the change is reverted, the pods are replaced with the revision of the snapshot
+	go func() {
+		time.Sleep(100 * time.Millisecond)
+		pods := client.CoreV1().Pods(MCO_NAMESPACE)
+		_ = pods.Delete("observability-thanos-rule-0", &metav1.DeleteOptions{})
+		_, _ = pods.Create(newRulePod("new", true))
+	}()
+	assert.NoError(t, tracker.WaitReplaced(10*time.Second))
+	assert.Contains(t, tracker.Check().Error(), "should have a new revision")
+}
+
+func TestCheckPodsReplaced(t *testing.T) {
+	s := workloadSnapshot{Kind: KindStatefulSet, Name: "observability-thanos-rule", Pods: []corev1.Pod{*newRulePod("old", true)}}
+	assert.Error(t, checkPodsReplaced(s, []corev1.Pod{*newRulePod("old", true)}, 1))
+	assert.Error(t, checkPodsReplaced(s, []corev1.Pod{*newRulePod("new", false)}, 1))
+	assert.Error(t, checkPodsReplaced(s, []corev1.Pod{}, 1))
+	assert.NoError(t, checkPodsReplaced(s, []corev1.Pod{*newRulePod("new", true)}, 1))
+}
@@ -9,13 +9,16 @@ type TestOptionsContainer struct {
 
 // Define options available for Tests to consume
 type TestOptions struct {
-	HubCluster      Cluster         `yaml:"hub"`
-	ManagedClusters []Cluster       `yaml:"clusters"`
-	ImageRegistry   Registry        `yaml:"imageRegistry,omitempty"`
-	KubeConfig      string          `yaml:"kubeconfig,omitempty"`
-	Connection      CloudConnection `yaml:"cloudConnection,omitempty"`
-	Headless        string          `yaml:"headless,omitempty"`
-	OwnerPrefix     string          `yaml:"ownerPrefix,omitempty"`
+	HubCluster                Cluster         `yaml:"hub"`
+	ManagedClusters           []Cluster       `yaml:"clusters"`
+	ImageRegistry             Registry        `yaml:"imageRegistry,omitempty"`
+	KubeConfig                string          `yaml:"kubeconfig,omitempty"`
+	Connection                CloudConnection `yaml:"cloudConnection,omitempty"`
+	Headless                  string          `yaml:"headless,omitempty"`
+	OwnerPrefix               string          `yaml:"ownerPrefix,omitempty"`
+	IngestionLatencyThreshold string          `yaml:"ingestionLatencyThreshold,omitempty"`
+	IngestionLatencyDuration  string          `yaml:"ingestionLatencyDuration,omitempty"`
+	Timeouts                  TimeoutOptions  `yaml:"timeouts,omitempty"`
 }
 
 // Define the shape of clusters that may be added under management
@@ -48,6 +51,8 @@ type CloudConnection struct {
 	SSHPublicKey  string  `yaml:"sshPublickey"`
 	Keys          APIKeys `yaml:"apiKeys,omitempty"`
 	OCPRelease    string  `yaml:"ocpRelease,omitempty"`
+	// one of aws, gcp and azure, the first one with a bucket configured in the apiKeys is used by default
+	ObjectStorageProvider string `yaml:"objectStorageProvider,omitempty"`
 }
 
 type APIKeys struct {
@@ -61,6 +66,13 @@ type AWSAPIKey struct {
 	AWSAccessSecret string `yaml:"awsSecretAccessKeyID"`
 	BaseDnsDomain   string `yaml:"baseDnsDomain"`
 	Region          string `yaml:"region"`
+	// the S3 bucket for the object storage, the endpoint can be any S3 compatible one, e.g. MinIO and Ceph RGW
+	Bucket             string `yaml:"bucket,omitempty"`
+	Endpoint           string `yaml:"endpoint,omitempty"`
+	Insecure           bool   `yaml:"insecure,omitempty"`
+	InsecureSkipVerify bool   `yaml:"insecureSkipVerify,omitempty"`
+	// path to the CA certificate of the endpoint
+	CACert string `yaml:"caCert,omitempty"`
 }
 
 type GCPAPIKey struct {
@@ -68,6 +80,7 @@ type GCPAPIKey struct {
 	ServiceAccountJsonKey string `yaml:"gcpServiceAccountJsonKey"`
 	BaseDnsDomain         string `yaml:"baseDnsDomain"`
 	Region                string `yaml:"region"`
+	Bucket                string `yaml:"bucket,omitempty"`
 }
 
 type AzureAPIKey struct {
@@ -78,4 +91,9 @@ type AzureAPIKey struct {
 	TenantID       string `yaml:"tenantID"`
 	ClientID       string `yaml:"clientID"`
 	ClientSecret   string `yaml:"clientSecret"`
+	// the blob storage for the object storage
+	StorageAccount    string `yaml:"storageAccount,omitempty"`
+	StorageAccountKey string `yaml:"storageAccountKey,omitempty"`
+	Container         string `yaml:"container,omitempty"`
+	Endpoint          string `yaml:"endpoint,omitempty"`
 }
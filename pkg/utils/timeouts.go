@@ -0,0 +1,150 @@
+// Copyright (c) 2021 Red Hat, Inc.
+// Copyright Contributors to the Open Cluster Management project
+
+package utils
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+)
+
+// TimeoutMultiplierEnv overrides the multiplier in the options, e.g. TIMEOUT_MULTIPLIER=2 for kind on the
+// shared CI
+const TimeoutMultiplierEnv = "TIMEOUT_MULTIPLIER"
+
+// TimeoutName is the name of a timeout in the catalog, the options can override it by the name
+type TimeoutName string
+
+const (
+	// an object is updated, or restored after it is changed manually
+	TimeoutObjectUpdate TimeoutName = "objectUpdate"
+	// the operators reconcile an object after its owner changed
+	TimeoutReconcile TimeoutName = "reconcile"
+	// an object is created and its pods are ready
+	TimeoutResourceReady TimeoutName = "resourceReady"
+	// the pods are restarted and ready after the config changed
+	TimeoutRolloutRestart TimeoutName = "rolloutRestart"
+	// the addon components scale to 0 after the addon is disabled
+	TimeoutAddonScaleDown TimeoutName = "addonScaleDown"
+	// the addon components are ready after the addon is enabled
+	TimeoutAddonReady TimeoutName = "addonReady"
+	// the observabilityaddon and the managedclusteraddon status follow the addon being disabled or enabled
+	TimeoutAddonStatus TimeoutName = "addonStatus"
+	// the metrics of the managed cluster stop being received after the addon is disabled
+	TimeoutAddonMetricsStopped TimeoutName = "addonMetricsStopped"
+	// the manifestwork of the managed cluster is recreated after it is deleted
+	TimeoutManifestWorkRecreate TimeoutName = "manifestWorkRecreate"
+	// a metric is queryable on the hub
+	TimeoutMetricVisible TimeoutName = "metricVisible"
+	// a metric is not queryable on the hub anymore
+	TimeoutMetricGone TimeoutName = "metricGone"
+	// a metric which is not collected stays absent on the hub
+	TimeoutMetricAbsent TimeoutName = "metricAbsent"
+	// an alert is fired and received by alertmanager
+	TimeoutAlertFired TimeoutName = "alertFired"
+	// the blocks are uploaded to the object storage
+	TimeoutBlocksUploaded TimeoutName = "blocksUploaded"
+	// the components match the availability config after it is switched
+	TimeoutAvailabilitySwitch TimeoutName = "availabilitySwitch"
+	// thanos-compact compacts, downsamples and applies the retention to the blocks
+	TimeoutCompaction TimeoutName = "compaction"
+	// the MCO of v1beta1 is installed and ready
+	TimeoutMCOInstall TimeoutName = "mcoInstall"
+	// all MCO components are ready
+	TimeoutMCOReady TimeoutName = "mcoReady"
+	// the MCO and addon components and namespaces are deleted
+	TimeoutUninstall TimeoutName = "uninstall"
+)
+
+// DefaultTimeouts is the timeout catalog before the multiplier is applied
+var DefaultTimeouts = map[TimeoutName]time.Duration{
+	TimeoutObjectUpdate:         1 * time.Minute,
+	TimeoutReconcile:            5 * time.Minute,
+	TimeoutResourceReady:        10 * time.Minute,
+	TimeoutRolloutRestart:       10 * time.Minute,
+	TimeoutAddonScaleDown:       5 * time.Minute,
+	TimeoutAddonReady:           6 * time.Minute,
+	TimeoutAddonStatus:          3 * time.Minute,
+	TimeoutAddonMetricsStopped:  2 * time.Minute,
+	TimeoutManifestWorkRecreate: 2 * time.Minute,
+	TimeoutMetricVisible:        10 * time.Minute,
+	TimeoutMetricGone:           10 * time.Minute,
+	TimeoutMetricAbsent:         2 * time.Minute,
+	TimeoutAlertFired:           5 * time.Minute,
+	TimeoutBlocksUploaded:       5 * time.Minute,
+	TimeoutAvailabilitySwitch:   15 * time.Minute,
+	TimeoutCompaction:           30 * time.Minute,
+	TimeoutMCOInstall:           20 * time.Minute,
+	TimeoutMCOReady:             25 * time.Minute,
+	TimeoutUninstall:            5 * time.Minute,
+}
+
+// TimeoutOptions overrides the timeout catalog, e.g.
+//
+//	timeouts:
+//	  multiplier: 2
+//	  durations:
+//	    mcoReady: 40m
+type TimeoutOptions struct {
+	Multiplier float64           `yaml:"multiplier,omitempty"`
+	Durations  map[string]string `yaml:"durations,omitempty"`
+}
+
+// ValidateTimeouts checks the overridden timeouts are in the catalog and the durations and the multiplier
+// are valid
+func ValidateTimeouts(opt TestOptions) error {
+	if _, err := getTimeoutMultiplier(opt); err != nil {
+		return err
+	}
+	for name, value := range opt.Timeouts.Durations {
+		if _, ok := DefaultTimeouts[TimeoutName(name)]; !ok {
+			return fmt.Errorf("unknown timeout %s", name)
+		}
+		d, err := time.ParseDuration(value)
+		if err != nil {
+			return fmt.Errorf("invalid duration %s of timeout %s: %v", value, name, err)
+		}
+		if d <= 0 {
+			return fmt.Errorf("the timeout %s should be positive but got %s", name, value)
+		}
+	}
+	return nil
+}
+
+// GetTimeout returns the timeout of the name in the options or the catalog, scaled by the multiplier
+func GetTimeout(opt TestOptions, name TimeoutName) time.Duration {
+	d, ok := DefaultTimeouts[name]
+	if !ok {
+		panic(fmt.Sprintf("unknown timeout %s", name))
+	}
+	if value, ok := opt.Timeouts.Durations[string(name)]; ok {
+		if override, err := time.ParseDuration(value); err == nil && override > 0 {
+			d = override
+		}
+	}
+	multiplier, err := getTimeoutMultiplier(opt)
+	if err != nil {
+		multiplier = 1
+	}
+	return time.Duration(float64(d) * multiplier)
+}
+
+func getTimeoutMultiplier(opt TestOptions) (float64, error) {
+	multiplier := opt.Timeouts.Multiplier
+	if value := os.Getenv(TimeoutMultiplierEnv); value != "" {
+		var err error
+		multiplier, err = strconv.ParseFloat(value, 64)
+		if err != nil {
+			return 0, fmt.Errorf("invalid %s %s: %v", TimeoutMultiplierEnv, value, err)
+		}
+	}
+	if multiplier == 0 {
+		return 1, nil
+	}
+	if multiplier < 0 {
+		return 0, fmt.Errorf("the timeout multiplier should be positive but got %v", multiplier)
+	}
+	return multiplier, nil
+}
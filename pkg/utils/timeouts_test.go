@@ -0,0 +1,36 @@
+// Copyright (c) 2021 Red Hat, Inc.
+// Copyright Contributors to the Open Cluster Management project
+
+package utils
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetTimeout(t *testing.T) {
+	os.Unsetenv(TimeoutMultiplierEnv)
+	opt := TestOptions{}
+	assert.NoError(t, ValidateTimeouts(opt))
+	assert.Equal(t, 25*time.Minute, GetTimeout(opt, TimeoutMCOReady))
+
+	opt.Timeouts = TimeoutOptions{Multiplier: 2, Durations: map[string]string{"mcoReady": "40m"}}
+	assert.NoError(t, ValidateTimeouts(opt))
+	assert.Equal(t, 80*time.Minute, GetTimeout(opt, TimeoutMCOReady))
+	assert.Equal(t, 2*time.Minute, GetTimeout(opt, TimeoutObjectUpdate))
+
+	os.Setenv(TimeoutMultiplierEnv, "1.5")
+	defer os.Unsetenv(TimeoutMultiplierEnv)
+	assert.Equal(t, 60*time.Minute, GetTimeout(opt, TimeoutMCOReady))
+
+	os.Setenv(TimeoutMultiplierEnv, "slow")
+	assert.Error(t, ValidateTimeouts(opt))
+	os.Unsetenv(TimeoutMultiplierEnv)
+
+	assert.Error(t, ValidateTimeouts(TestOptions{Timeouts: TimeoutOptions{Durations: map[string]string{"unknown": "1m"}}}))
+	assert.Error(t, ValidateTimeouts(TestOptions{Timeouts: TimeoutOptions{Durations: map[string]string{"mcoReady": "1"}}}))
+	assert.Error(t, ValidateTimeouts(TestOptions{Timeouts: TimeoutOptions{Multiplier: -1}}))
+}
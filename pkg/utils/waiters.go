@@ -0,0 +1,257 @@
+// Copyright (c) 2021 Red Hat, Inc.
+// Copyright Contributors to the Open Cluster Management project
+
+package utils
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/cache"
+	watchtools "k8s.io/client-go/tools/watch"
+	"k8s.io/klog"
+)
+
+// Transition is a state of the waited objects observed by a waiter
+type Transition struct {
+	Time  time.Time
+	State string
+}
+
+// WaitTimeoutError is returned by the waiters when the state is not reached in time, with the states
+// observed while waiting
+type WaitTimeoutError struct {
+	What        string
+	Timeout     time.Duration
+	Transitions []Transition
+}
+
+func (e *WaitTimeoutError) Error() string {
+	msgs := []string{fmt.Sprintf("timed out after %v waiting for %s, observed:", e.Timeout, e.What)}
+	for _, t := range e.Transitions {
+		msgs = append(msgs, fmt.Sprintf("  %s %s", t.Time.UTC().Format(time.RFC3339), t.State))
+	}
+	return strings.Join(msgs, "\n")
+}
+
+// stateFunc returns true if the objects in the store reached the state, and the description of the state
+type stateFunc func(store cache.Store) (bool, string)
+
+// waitForState watches the objects of the lister watcher and returns as soon as they reach the state
+func waitForState(lw cache.ListerWatcher, objType runtime.Object, what string, timeout time.Duration, state stateFunc) error {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	var (
+		mu          sync.Mutex
+		store       cache.Store
+		transitions []Transition
+	)
+	check := func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		done, desc := state(store)
+		if len(transitions) == 0 || transitions[len(transitions)-1].State != desc {
+			klog.V(2).Infof("Waiting for %s: %s", what, desc)
+			transitions = append(transitions, Transition{Time: time.Now(), State: desc})
+		}
+		return done
+	}
+
+	_, err := watchtools.UntilWithSync(ctx, lw, objType,
+		func(s cache.Store) (bool, error) {
+			mu.Lock()
+			store = s
+			mu.Unlock()
+			return check(), nil
+		},
+		func(watch.Event) (bool, error) {
+			return check(), nil
+		})
+	if err == nil {
+		return nil
+	}
+	if ctx.Err() == nil {
+		return fmt.Errorf("failed to wait for %s: %v", what, err)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	return &WaitTimeoutError{What: what, Timeout: timeout, Transitions: transitions}
+}
+
+func withFieldSelector(name string, options metav1.ListOptions) metav1.ListOptions {
+	if name != "" {
+		options.FieldSelector = fields.OneTermEqualSelector("metadata.name", name).String()
+	}
+	return options
+}
+
+// unstructuredListWatch returns the lister watcher of the objects, all objects in the namespace if the
+// name is empty
+func unstructuredListWatch(opt TestOptions, isHub bool, gvr schema.GroupVersionResource, namespace, name string) cache.ListerWatcher {
+	return resourceListWatch(GetKubeClientDynamic(opt, isHub).Resource(gvr).Namespace(namespace), name)
+}
+
+// resourceListWatch returns the lister watcher of the objects of the dynamic client, all objects if the
+// name is empty
+func resourceListWatch(client dynamic.ResourceInterface, name string) cache.ListerWatcher {
+	return &cache.ListWatch{
+		ListFunc: func(options metav1.ListOptions) (runtime.Object, error) {
+			return client.List(withFieldSelector(name, options))
+		},
+		WatchFunc: func(options metav1.ListOptions) (watch.Interface, error) {
+			return client.Watch(withFieldSelector(name, options))
+		},
+	}
+}
+
+// workloadListWatch returns the lister watcher of the deployment or statefulset and its object type
+func workloadListWatch(client kubernetes.Interface, kind, name, namespace string) (cache.ListerWatcher, runtime.Object, error) {
+	switch kind {
+	case KindDeployment:
+		deploys := client.AppsV1().Deployments(namespace)
+		return &cache.ListWatch{
+			ListFunc: func(options metav1.ListOptions) (runtime.Object, error) {
+				return deploys.List(withFieldSelector(name, options))
+			},
+			WatchFunc: func(options metav1.ListOptions) (watch.Interface, error) {
+				return deploys.Watch(withFieldSelector(name, options))
+			},
+		}, &appsv1.Deployment{}, nil
+	case KindStatefulSet:
+		sts := client.AppsV1().StatefulSets(namespace)
+		return &cache.ListWatch{
+			ListFunc: func(options metav1.ListOptions) (runtime.Object, error) {
+				return sts.List(withFieldSelector(name, options))
+			},
+			WatchFunc: func(options metav1.ListOptions) (watch.Interface, error) {
+				return sts.Watch(withFieldSelector(name, options))
+			},
+		}, &appsv1.StatefulSet{}, nil
+	}
+	return nil, nil, fmt.Errorf("unknown workload kind %s", kind)
+}
+
+// podListWatch returns the lister watcher of the pods of the label selector
+func podListWatch(client kubernetes.Interface, namespace, labelSelector string) cache.ListerWatcher {
+	pods := client.CoreV1().Pods(namespace)
+	return &cache.ListWatch{
+		ListFunc: func(options metav1.ListOptions) (runtime.Object, error) {
+			options.LabelSelector = labelSelector
+			return pods.List(options)
+		},
+		WatchFunc: func(options metav1.ListOptions) (watch.Interface, error) {
+			options.LabelSelector = labelSelector
+			return pods.Watch(options)
+		},
+	}
+}
+
+// WaitForRollout waits for the deployment or statefulset to roll out its latest spec with all replicas
+// updated and ready
+func WaitForRollout(opt TestOptions, isHub bool, kind, name, namespace string, timeout time.Duration) error {
+	lw, objType, err := workloadListWatch(getKubeClient(opt, isHub), kind, name, namespace)
+	if err != nil {
+		return err
+	}
+
+	what := fmt.Sprintf("%s %s/%s to roll out", kind, namespace, name)
+	return waitForState(lw, objType, what, timeout, func(store cache.Store) (bool, string) {
+		objs := store.List()
+		if len(objs) == 0 {
+			return false, "not found"
+		}
+		var w Workload
+		switch obj := objs[0].(type) {
+		case *appsv1.Deployment:
+			w = deploymentWorkload(*obj)
+		case *appsv1.StatefulSet:
+			w = statefulSetWorkload(*obj)
+		}
+		if err := w.CheckRolledOut(); err != nil {
+			return false, err.Error()
+		}
+		return true, "rolled out"
+	})
+}
+
+// WaitForPodsReplaced waits for all the old pods of the label selector to be gone and the new pods to be
+// ready, at least one new pod is expected
+func WaitForPodsReplaced(opt TestOptions, isHub bool, namespace, labelSelector string, oldPods []corev1.Pod, timeout time.Duration) error {
+	lw := podListWatch(getKubeClient(opt, isHub), namespace, labelSelector)
+	oldUIDs := map[types.UID]bool{}
+	for _, pod := range oldPods {
+		oldUIDs[pod.UID] = true
+	}
+
+	what := fmt.Sprintf("pods %s in %s to be replaced", labelSelector, namespace)
+	return waitForState(lw, &corev1.Pod{}, what, timeout, func(store cache.Store) (bool, string) {
+		old, notReady, ready := 0, 0, 0
+		for _, obj := range store.List() {
+			pod := obj.(*corev1.Pod)
+			switch {
+			case oldUIDs[pod.UID]:
+				old++
+			case IsPodReady(*pod):
+				ready++
+			default:
+				notReady++
+			}
+		}
+		desc := fmt.Sprintf("%d old, %d new not ready and %d new ready pods", old, notReady, ready)
+		return old == 0 && notReady == 0 && ready > 0, desc
+	})
+}
+
+// WaitForDeleted waits for the object to be deleted, or all objects in the namespace if the name is empty
+func WaitForDeleted(opt TestOptions, isHub bool, gvr schema.GroupVersionResource, namespace, name string, timeout time.Duration) error {
+	lw := unstructuredListWatch(opt, isHub, gvr, namespace, name)
+	what := fmt.Sprintf("%s %s/%s to be deleted", gvr.Resource, namespace, name)
+	if name == "" {
+		what = fmt.Sprintf("all %s in %s to be deleted", gvr.Resource, namespace)
+	}
+	return waitForState(lw, &unstructured.Unstructured{}, what, timeout, func(store cache.Store) (bool, string) {
+		names := store.ListKeys()
+		if len(names) == 0 {
+			return true, "deleted"
+		}
+		return false, fmt.Sprintf("%d left: %s", len(names), strings.Join(names, ", "))
+	})
+}
+
+// WaitForCondition waits for the custom resource to have the condition of the type with the status, e.g.
+// the Ready condition of the MCO CR with the status True
+func WaitForCondition(opt TestOptions, isHub bool, gvr schema.GroupVersionResource, namespace, name, conditionType, status string, timeout time.Duration) error {
+	lw := unstructuredListWatch(opt, isHub, gvr, namespace, name)
+	what := fmt.Sprintf("%s %s/%s to have condition %s=%s", gvr.Resource, namespace, name, conditionType, status)
+	return waitForState(lw, &unstructured.Unstructured{}, what, timeout, func(store cache.Store) (bool, string) {
+		objs := store.List()
+		if len(objs) == 0 {
+			return false, "not found"
+		}
+		conditions, _, _ := unstructured.NestedSlice(objs[0].(*unstructured.Unstructured).Object, "status", "conditions")
+		for _, c := range conditions {
+			condition, ok := c.(map[string]interface{})
+			if !ok || condition["type"] != conditionType {
+				continue
+			}
+			desc := fmt.Sprintf("%s=%v %v: %v", conditionType, condition["status"], condition["reason"], condition["message"])
+			return condition["status"] == status, desc
+		}
+		return false, fmt.Sprintf("no condition %s", conditionType)
+	})
+}
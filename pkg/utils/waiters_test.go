@@ -0,0 +1,54 @@
+// Copyright (c) 2021 Red Hat, Inc.
+// Copyright Contributors to the Open Cluster Management project
+
+package utils
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/tools/cache"
+)
+
+func podCount(store cache.Store) (bool, string) {
+	n := len(store.List())
+	if n == 0 {
+		return true, "deleted"
+	}
+	return false, "pods left"
+}
+
+func TestWaitForState(t *testing.T) {
+	pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "thanos-rule-0", Namespace: MCO_NAMESPACE}}
+	client := fake.NewSimpleClientset(pod)
+	pods := client.CoreV1().Pods(MCO_NAMESPACE)
+	lw := &cache.ListWatch{
+		ListFunc: func(options metav1.ListOptions) (runtime.Object, error) {
+			return pods.List(options)
+		},
+		WatchFunc: func(options metav1.ListOptions) (watch.Interface, error) {
+			return pods.Watch(options)
+		},
+	}
+
+	err := waitForState(lw, &corev1.Pod{}, "pods to be deleted", 200*time.Millisecond, podCount)
+	require.Error(t, err)
+	timeoutErr, ok := err.(*WaitTimeoutError)
+	require.True(t, ok)
+	require.Len(t, timeoutErr.Transitions, 1)
+	assert.Equal(t, "pods left", timeoutErr.Transitions[0].State)
+	assert.Contains(t, err.Error(), "timed out after 200ms waiting for pods to be deleted")
+
+	go func() {
+		time.Sleep(100 * time.Millisecond)
+		_ = pods.Delete(pod.Name, &metav1.DeleteOptions{})
+	}()
+	assert.NoError(t, waitForState(lw, &corev1.Pod{}, "pods to be deleted", 10*time.Second, podCount))
+}